@@ -0,0 +1,143 @@
+package sprigmath
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// decimalScale returns the number of digits after the point that v was
+// written with, so that amounts such as "100.00" are split into cents
+func decimalScale(v interface{}, r *big.Rat) (int, bool) {
+	text := ""
+	switch x := v.(type) {
+	case Decimal:
+		return x.scale, true
+	case json.Number:
+		text = string(x)
+	case string:
+		text = strings.TrimSpace(x)
+	}
+
+	prec, exact := r.FloatPrec()
+	if i := strings.IndexByte(text, '.'); i != -1 && !strings.ContainsAny(text, "eEpPxX") {
+		if digits := len(text) - i - 1; digits > prec {
+			prec = digits
+		}
+	}
+	return prec, exact
+}
+
+// maxParts is the most parts that allocate splits a total into
+const maxParts = 10000
+
+// allocateRat splits total into parts proportional to ratios, with the same
+// number of digits after the point as total. Parts are rounded down, and
+// the units that are left over go to the parts with the largest remainders.
+// A float total is rejected, as it does not say how many digits it has.
+func (f *funcs) allocateRat(name string, total interface{}, ratios []*big.Rat) ([]Decimal, error) {
+	switch reflect.ValueOf(total).Kind() {
+	case reflect.Float32, reflect.Float64:
+		return nil, argError(name, 0, total, newNumError(ErrDomain, "%v is a float, use a string or a Decimal to give its digits", total))
+	}
+	lit, err := f.literal(total)
+	if err != nil {
+		return nil, argError(name, 0, total, err)
+	}
+	t, err := toRat(lit)
+	if err != nil {
+		return nil, argError(name, 0, total, err)
+	}
+
+	scale, exact := decimalScale(lit, t)
+	if !exact {
+		return nil, argError(name, 0, total, newNumError(ErrDomain, "%v cannot be split exactly", total))
+	}
+
+	sum := new(big.Rat)
+	for _, r := range ratios {
+		if r.Sign() < 0 {
+			return nil, argError(name, 1, ratios, newNumError(ErrDomain, "ratios must not be negative"))
+		}
+		sum.Add(sum, r)
+	}
+	if sum.Sign() == 0 {
+		return nil, argError(name, 1, ratios, newNumError(ErrDomain, "ratios must not all be zero"))
+	}
+
+	pow := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil)
+	units := new(big.Rat).Mul(t, new(big.Rat).SetInt(pow)).Num()
+	neg := units.Sign() < 0
+	units.Abs(units)
+
+	parts := make([]*big.Int, len(ratios))
+	rems := make([]*big.Rat, len(ratios))
+	left := new(big.Int).Set(units)
+	for i, r := range ratios {
+		share := new(big.Rat).Mul(new(big.Rat).SetInt(units), r)
+		share.Quo(share, sum)
+		parts[i] = new(big.Int).Quo(share.Num(), share.Denom())
+		rems[i] = share.Sub(share, new(big.Rat).SetInt(parts[i]))
+		left.Sub(left, parts[i])
+	}
+
+	order := make([]int, len(ratios))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return rems[order[a]].Cmp(rems[order[b]]) > 0
+	})
+	for i := 0; left.Sign() > 0; i++ {
+		parts[order[i]].Add(parts[order[i]], big.NewInt(1))
+		left.Sub(left, big.NewInt(1))
+	}
+
+	result := make([]Decimal, len(parts))
+	for i, p := range parts {
+		if neg {
+			p.Neg(p)
+		}
+		result[i] = Decimal{new(big.Rat).SetFrac(p, pow), scale}
+	}
+	return result, nil
+}
+
+// allocate splits total into n parts that differ by at most one unit of the
+// last digit of total, and sum to exactly total
+func (f *funcs) allocate(total interface{}, n interface{}) ([]Decimal, error) {
+	count, err := f.toInt(n)
+	if err != nil {
+		return nil, argError("allocate", 1, n, err)
+	}
+	if count < 1 || count > maxParts {
+		return nil, argError("allocate", 1, n, newNumError(ErrDomain, "cannot split into %v parts", n))
+	}
+
+	ratios := make([]*big.Rat, count)
+	for i := range ratios {
+		ratios[i] = big.NewRat(1, 1)
+	}
+	return f.allocateRat("allocate", total, ratios)
+}
+
+// allocateRatios splits total into parts proportional to a list of ratios,
+// that sum to exactly total
+func (f *funcs) allocateRatios(total interface{}, ratios interface{}) ([]Decimal, error) {
+	rv := reflect.ValueOf(ratios)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array || rv.Len() == 0 {
+		return nil, argError("allocateRatios", 1, ratios, newNumError(ErrDomain, "ratios must be a non-empty list"))
+	}
+
+	rats := make([]*big.Rat, rv.Len())
+	for i := range rats {
+		r, err := f.toRat(rv.Index(i).Interface())
+		if err != nil {
+			return nil, argError("allocateRatios", 1, ratios, err)
+		}
+		rats[i] = r
+	}
+	return f.allocateRat("allocateRatios", total, rats)
+}
@@ -0,0 +1,52 @@
+package sprigmath
+
+import (
+	"testing"
+)
+
+func TestAllocate(t *testing.T) {
+	for tpl, expected := range map[string]string{
+		`{{ allocate "100.00" 3 }}`:                              "[33.34 33.33 33.33]",
+		`{{ allocate (roundCurrency "USD" 100) 3 }}`:             "[33.34 33.33 33.33]",
+		`{{ allocate 100 3 }}`:                                   "[34 33 33]",
+		`{{ allocate "-0.05" 3 }}`:                               "[-0.02 -0.02 -0.01]",
+		`{{ allocate "0.1" 4 }}`:                                 "[0.1 0.0 0.0 0.0]",
+		`{{ allocate "0.10" 4 }}`:                                "[0.03 0.03 0.02 0.02]",
+		`{{ allocateRatios "100.00" (list 1 2) }}`:               "[33.33 66.67]",
+		`{{ allocateRatios "10" (list 0.5 0.3 0.2) }}`:           "[5 3 2]",
+		`{{ allocateRatios "0.05" (list 3 7) }}`:                 "[0.02 0.03]",
+		`{{ allocateRatios "100.00" (list 1 0 1) }}`:             "[50.00 0.00 50.00]",
+		`{{ range allocate "1.00" 3 }}{{ . | add 0 }} {{ end }}`: "0.34 0.33 0.33 ",
+	} {
+		if out, err := runRaw(tpl, nil); err != nil || out != expected {
+			t.Errorf("%s: expected %q, got %q %v", tpl, expected, out, err)
+		}
+	}
+
+	if err := runerr(`{{ allocate 100 0 }}`, "allocate[arg1]: cannot split into 0 parts"); err != nil {
+		t.Error(err)
+	}
+	if err := runerr(`{{ allocate 100 10001 }}`, "allocate[arg1]: cannot split into 10001 parts"); err != nil {
+		t.Error(err)
+	}
+	for tpl, errstr := range map[string]string{
+		`{{ allocate 0.1 4 }}`:                  "allocate[arg0]: 0.1 is a float, use a string or a Decimal to give its digits",
+		`{{ allocate 100.00 3 }}`:               "allocate[arg0]: 100 is a float, use a string or a Decimal to give its digits",
+		`{{ allocateRatios 100.5 (list 1 2) }}`: "allocateRatios[arg0]: 100.5 is a float, use a string or a Decimal to give its digits",
+	} {
+		if err := runerr(tpl, errstr); err != nil {
+			t.Error(err)
+		}
+	}
+
+	// the parts add up exactly
+	if err := runt(`{{ $s := 0 }}{{ range allocate "0.30" 3 }}{{ $s = add $s . }}{{ end }}{{ $s }}`, "0.30"); err != nil {
+		t.Error(err)
+	}
+	if err := runerr(`{{ allocateRatios 100 (list 0 0) }}`, "allocateRatios[arg1]: ratios must not all be zero"); err != nil {
+		t.Error(err)
+	}
+	if err := runerr(`{{ allocateRatios 100 (list 1 -1) }}`, "allocateRatios[arg1]: ratios must not be negative"); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,237 @@
+package sprigmath
+
+import (
+	"fmt"
+)
+
+// Number is the result of the functions in the Go API. It holds an int64 or a
+// float64, or a Rational, Quantity or Null where the template functions would
+// return one. Number implements Int64er and Float64er, so it can be passed
+// back to any function.
+type Number struct {
+	v interface{}
+}
+
+// Value returns the number as an int64, a float64, or one of this library's
+// types
+func (n Number) Value() interface{} {
+	return n.v
+}
+
+// IsInt reports whether the number is an int64
+func (n Number) IsInt() bool {
+	_, ok := n.v.(int64)
+	return ok
+}
+
+// Int64 returns the number if it is an int64, and an error otherwise
+func (n Number) Int64() (int64, error) {
+	if iv, ok := n.v.(int64); ok {
+		return iv, nil
+	}
+	return 0, newNumError(ErrDomain, "%v is not an int64", n.v)
+}
+
+// Float64 returns the number as a float64
+func (n Number) Float64() (float64, error) {
+	return toFloat64(n.v)
+}
+
+// String renders the number the way a template would
+func (n Number) String() string {
+	return fmt.Sprint(n.v)
+}
+
+// Math implements the template functions as a Go API, with the same
+// conversions and int/float rules, so that Go code can calculate exactly what
+// a template would. Its FuncMap also includes any functions registered with
+// it.
+type Math struct {
+	f *funcs
+
+	// custom are the functions added with Register, already wrapped
+	custom map[string]interface{}
+
+	// info documents custom functions, as set by Describe
+	info map[string]FuncInfo
+}
+
+// NewMath returns the Go API for a set of options. FloatFormat does not
+// apply, as results are returned as a Number.
+func NewMath(opts Options) *Math {
+	return &Math{
+		f:      &funcs{Options: opts},
+		custom: map[string]interface{}{},
+		info:   map[string]FuncInfo{},
+	}
+}
+
+var defaultMath = NewMath(Options{})
+
+// apply calls fn with args, applying Options.Missing as FuncMap does
+func (m *Math) apply(fn func(args []interface{}) (interface{}, error), args ...interface{}) (Number, error) {
+	for i, a := range args {
+		if n, ok := a.(Number); ok {
+			args[i] = n.v
+		}
+		if !isMissing(args[i]) {
+			continue
+		}
+		switch m.f.Missing {
+		case MissingZero:
+			args[i] = int64(0)
+		case MissingNull:
+			return Number{Null{}}, nil
+		}
+	}
+
+	r, err := fn(args)
+	if err != nil {
+		return Number{}, err
+	}
+	return Number{r}, nil
+}
+
+// ToNumber converts v to an int64 or a float64, like the number function
+func (m *Math) ToNumber(v interface{}) (Number, error) {
+	return m.apply(func(a []interface{}) (interface{}, error) { return m.f.number(a[0]) }, v)
+}
+
+// Add is the add function
+func (m *Math) Add(a, b interface{}) (Number, error) {
+	return m.apply(func(a []interface{}) (interface{}, error) { return m.f.add(a[0], a[1]) }, a, b)
+}
+
+// Sub is the sub function
+func (m *Math) Sub(a, b interface{}) (Number, error) {
+	return m.apply(func(a []interface{}) (interface{}, error) { return m.f.sub(a[0], a[1]) }, a, b)
+}
+
+// Mul is the mul function
+func (m *Math) Mul(a, b interface{}) (Number, error) {
+	return m.apply(func(a []interface{}) (interface{}, error) { return m.f.mul(a[0], a[1]) }, a, b)
+}
+
+// Div is the div function
+func (m *Math) Div(a, b interface{}) (Number, error) {
+	return m.apply(func(a []interface{}) (interface{}, error) { return m.f.div(a[0], a[1]) }, a, b)
+}
+
+// Mod is the mod function
+func (m *Math) Mod(a, b interface{}) (Number, error) {
+	return m.apply(func(a []interface{}) (interface{}, error) { return m.f.mod(a[0], a[1]) }, a, b)
+}
+
+// Max is the max function
+func (m *Math) Max(a, b interface{}) (Number, error) {
+	return m.apply(func(a []interface{}) (interface{}, error) { return m.f.max(a[0], a[1]) }, a, b)
+}
+
+// Min is the min function
+func (m *Math) Min(a, b interface{}) (Number, error) {
+	return m.apply(func(a []interface{}) (interface{}, error) { return m.f.min(a[0], a[1]) }, a, b)
+}
+
+// Pow is the pow function
+func (m *Math) Pow(x, y interface{}) (Number, error) {
+	return m.apply(func(a []interface{}) (interface{}, error) { return m.f.pow(a[0], a[1]) }, x, y)
+}
+
+// Sqrt is the sqrt function
+func (m *Math) Sqrt(v interface{}) (Number, error) {
+	return m.apply(func(a []interface{}) (interface{}, error) { return m.f.sqrt(a[0]) }, v)
+}
+
+// Round is the round function
+func (m *Math) Round(v interface{}) (Number, error) {
+	return m.apply(func(a []interface{}) (interface{}, error) { return m.f.round(a[0]) }, v)
+}
+
+// Ceil is the ceil function
+func (m *Math) Ceil(v interface{}) (Number, error) {
+	return m.apply(func(a []interface{}) (interface{}, error) { return m.f.ceil(a[0]) }, v)
+}
+
+// Floor is the floor function
+func (m *Math) Floor(v interface{}) (Number, error) {
+	return m.apply(func(a []interface{}) (interface{}, error) { return m.f.floor(a[0]) }, v)
+}
+
+// Compare returns -1, 0 or +1 like numLt and numEq, and false when either
+// value is NaN
+func (m *Math) Compare(a, b interface{}) (int, bool, error) {
+	if n, ok := a.(Number); ok {
+		a = n.v
+	}
+	if n, ok := b.(Number); ok {
+		b = n.v
+	}
+	return m.f.compare("Compare", 0, a, b)
+}
+
+// ToNumber converts v with the default options
+func ToNumber(v interface{}) (Number, error) { return defaultMath.ToNumber(v) }
+
+// Add adds two values with the default options, like the add function
+func Add(a, b interface{}) (Number, error) { return defaultMath.Add(a, b) }
+
+// Sub subtracts b from a with the default options
+func Sub(a, b interface{}) (Number, error) { return defaultMath.Sub(a, b) }
+
+// Mul multiplies two values with the default options
+func Mul(a, b interface{}) (Number, error) { return defaultMath.Mul(a, b) }
+
+// Div divides a by b with the default options
+func Div(a, b interface{}) (Number, error) { return defaultMath.Div(a, b) }
+
+// Mod is the remainder of a divided by b with the default options
+func Mod(a, b interface{}) (Number, error) { return defaultMath.Mod(a, b) }
+
+// Max is the larger of two values with the default options
+func Max(a, b interface{}) (Number, error) { return defaultMath.Max(a, b) }
+
+// Min is the smaller of two values with the default options
+func Min(a, b interface{}) (Number, error) { return defaultMath.Min(a, b) }
+
+// Numeric is the constraint for the generic functions: the Go integer and
+// float types, and types based on them
+type Numeric interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		~float32 | ~float64
+}
+
+func toInterfaces[T Numeric](xs []T) []interface{} {
+	args := make([]interface{}, len(xs))
+	for i, x := range xs {
+		args[i] = x
+	}
+	return args
+}
+
+// Sum adds xs like the add function: the result is an int64 when they are all
+// integers, and a float64 otherwise. The sum of nothing is 0.
+func Sum[T Numeric](xs []T) (Number, error) {
+	if len(xs) == 0 {
+		return Number{int64(0)}, nil
+	}
+	args := toInterfaces(xs)
+	r, err := defaultMath.f.add(args[0], args[1:]...)
+	if err != nil {
+		return Number{}, err
+	}
+	return Number{r}, nil
+}
+
+// Product multiplies xs like the mul function. The product of nothing is 1.
+func Product[T Numeric](xs []T) (Number, error) {
+	if len(xs) == 0 {
+		return Number{int64(1)}, nil
+	}
+	args := toInterfaces(xs)
+	r, err := defaultMath.f.mul(args[0], args[1:]...)
+	if err != nil {
+		return Number{}, err
+	}
+	return Number{r}, nil
+}
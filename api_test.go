@@ -0,0 +1,107 @@
+package sprigmath
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestAPI(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		fn       func() (Number, error)
+		expected interface{}
+	}{
+		{"Add ints", func() (Number, error) { return Add(1, "2") }, int64(3)},
+		{"Add floats", func() (Number, error) { return Add(1, 2.5) }, 3.5},
+		{"Add json", func() (Number, error) { return Add(json.Number("2"), uint8(3)) }, int64(5)},
+		{"Sub", func() (Number, error) { return Sub(10, 4) }, int64(6)},
+		{"Mul", func() (Number, error) { return Mul("1e3", 3) }, int64(3000)},
+		{"Div", func() (Number, error) { return Div(3, 2) }, 1.5},
+		{"Mod", func() (Number, error) { return Mod(7, 3) }, int64(1)},
+		{"Max", func() (Number, error) { return Max(7, 3.5) }, 7.0},
+		{"Min", func() (Number, error) { return Min(7, 3) }, int64(3)},
+		{"ToNumber", func() (Number, error) { return ToNumber(" 42 ") }, int64(42)},
+		{"Sum ints", func() (Number, error) { return Sum([]int{1, 2, 3}) }, int64(6)},
+		{"Sum floats", func() (Number, error) { return Sum([]float64{0.5, 0.25}) }, 0.75},
+		{"Sum empty", func() (Number, error) { return Sum([]uint16{}) }, int64(0)},
+		{"Product", func() (Number, error) { return Product([]int32{2, 3, 4}) }, int64(24)},
+		{"Product empty", func() (Number, error) { return Product([]float32{}) }, int64(1)},
+	} {
+		n, err := tc.fn()
+		if err != nil || n.Value() != tc.expected {
+			t.Errorf("%s: expected %T %v, got %T %v %v", tc.name, tc.expected, tc.expected, n.Value(), n.Value(), err)
+		}
+	}
+
+	// results can be passed back in
+	n, _ := Add(1, 2)
+	if r, err := Mul(n, n); err != nil || r.Value() != int64(9) {
+		t.Errorf("expected 9, got %v %v", r, err)
+	}
+	if !n.IsInt() || n.String() != "3" {
+		t.Errorf("expected an int64 3, got %v", n)
+	}
+	half, _ := Div(1, 2)
+	if _, err := half.Int64(); !errors.Is(err, ErrDomain) {
+		t.Errorf("expected ErrDomain, got %v", err)
+	}
+	if fv, err := half.Float64(); err != nil || fv != 0.5 {
+		t.Errorf("expected 0.5, got %v %v", fv, err)
+	}
+	if fv, err := toFloat64(half); err != nil || fv != 0.5 {
+		t.Errorf("expected a Number to convert to 0.5, got %v %v", fv, err)
+	}
+
+	if _, err := Add(1, "x"); err == nil || err.Error() != "add[arg1]: x is not a float64 or int64" {
+		t.Errorf("expected an add error, got %v", err)
+	}
+
+	type celsius float64
+	if n, err := Sum([]celsius{20, 1.5}); err != nil || n.Value() != 21.5 {
+		t.Errorf("expected 21.5, got %v %v", n, err)
+	}
+}
+
+func TestMath(t *testing.T) {
+	strict := NewMath(Options{Strict: true})
+	if _, err := strict.Sqrt(-1); !errors.Is(err, ErrDomain) {
+		t.Errorf("expected ErrDomain, got %v", err)
+	}
+	if n, err := NewMath(Options{}).Sqrt(-1); err != nil || !math.IsNaN(n.Value().(float64)) {
+		t.Errorf("expected NaN, got %v %v", n, err)
+	}
+	if _, err := strict.Div(1, 0.0); !errors.Is(err, ErrDivideByZero) {
+		t.Errorf("expected ErrDivideByZero, got %v", err)
+	}
+
+	m := NewMath(Options{IntegralFloats: true})
+	if n, err := m.Mul(1000.0, 1000.0); err != nil || n.Value() != int64(1000000) {
+		t.Errorf("expected 1000000, got %v %v", n, err)
+	}
+	if n, err := m.Round(2.5); err != nil || n.Value() != int64(3) {
+		t.Errorf("expected 3, got %v %v", n, err)
+	}
+	if n, err := m.Pow(2, 10); err != nil || n.Value() != 1024.0 {
+		t.Errorf("expected 1024, got %v %v", n, err)
+	}
+
+	if c, ok, err := m.Compare(3, "3.0"); err != nil || !ok || c != 0 {
+		t.Errorf("expected equal, got %v %v %v", c, ok, err)
+	}
+	if _, ok, err := m.Compare(math.NaN(), 1); err != nil || ok {
+		t.Errorf("expected NaN to be unordered, got %v %v", ok, err)
+	}
+
+	if _, err := m.Add(nil, 1); !errors.Is(err, ErrMissing) {
+		t.Errorf("expected ErrMissing, got %v", err)
+	}
+	if n, err := NewMath(Options{Missing: MissingZero}).Add(nil, 1); err != nil || n.Value() != int64(1) {
+		t.Errorf("expected 1, got %v %v", n, err)
+	}
+	if n, err := NewMath(Options{Missing: MissingNull}).Add(nil, 1); err != nil || n.Value() != (Null{}) || n.String() != "" {
+		t.Errorf("expected Null, got %v %v", n, err)
+	}
+}
@@ -0,0 +1,118 @@
+package sprigmath
+
+import (
+	"math"
+	"math/big"
+)
+
+// The assert functions return their value unchanged when it passes, so they
+// can be used inline, and otherwise stop rendering with an error wrapping
+// ErrAssertion. The first argument names the value in the message, and the
+// value is last so that it can be piped in, as in
+// `.Values.replicas | assertRange "replicas" 1 100`.
+
+func assertName(name string) string {
+	if name == "" {
+		return "value"
+	}
+	return name
+}
+
+// assertNumber converts the value of an assert function, reporting values
+// that are not numbers with the name they were given
+func (f *funcs) assertNumber(fn string, i int, v interface{}, name string) (int64, float64, bool, error) {
+	iv, fv, isFloat, err := f.numberArg(fn, i, v)
+	if err != nil {
+		return 0, 0, false, argError(fn, i, v, newNumError(ErrNotNumber, "%s must be a number, got %v", name, v))
+	}
+	return iv, fv, isFloat, nil
+}
+
+func assertionError(fn string, i int, v interface{}, format string, args ...interface{}) error {
+	return argError(fn, i, v, newNumError(ErrAssertion, format, args...))
+}
+
+// assertRange checks that lo <= v <= hi
+func (f *funcs) assertRange(name string, lo interface{}, hi interface{}, v interface{}) (interface{}, error) {
+	name = assertName(name)
+	if _, _, _, err := f.assertNumber("assertRange", 3, v, name); err != nil {
+		return nil, err
+	}
+
+	c, ok, err := f.compare("assertRange", 1, lo, v)
+	if err != nil {
+		return nil, err
+	}
+	if ok && c <= 0 {
+		c, ok, err = f.compare("assertRange", 2, hi, v)
+		if err != nil {
+			return nil, err
+		}
+		if ok && c >= 0 {
+			return v, nil
+		}
+	} else if _, _, _, err := f.numberArg("assertRange", 2, hi); err != nil {
+		return nil, err
+	}
+
+	return nil, assertionError("assertRange", 3, v, "%s must be between %v and %v, got %v", name, lo, hi, v)
+}
+
+// assertPositive checks that v > 0
+func (f *funcs) assertPositive(name string, v interface{}) (interface{}, error) {
+	name = assertName(name)
+	_, fv, _, err := f.assertNumber("assertPositive", 1, v, name)
+	if err != nil {
+		return nil, err
+	}
+	if !(fv > 0) {
+		return nil, assertionError("assertPositive", 1, v, "%s must be positive, got %v", name, v)
+	}
+	return v, nil
+}
+
+// assertInt checks that v is a whole number in the int64 range
+func (f *funcs) assertInt(name string, v interface{}) (interface{}, error) {
+	name = assertName(name)
+	if _, _, _, err := f.assertNumber("assertInt", 1, v, name); err != nil {
+		return nil, err
+	}
+	if _, ok := f.intValue(v); !ok {
+		return nil, assertionError("assertInt", 1, v, "%s must be a whole number, got %v", name, v)
+	}
+	return v, nil
+}
+
+// assertMultipleOf checks that v is a whole multiple of m. Both are compared
+// as exact decimals, so 0.3 is a multiple of 0.1.
+func (f *funcs) assertMultipleOf(name string, m interface{}, v interface{}) (interface{}, error) {
+	name = assertName(name)
+
+	_, mf, _, err := f.numberArg("assertMultipleOf", 1, m)
+	if err != nil {
+		return nil, err
+	}
+	if mf == 0 {
+		return nil, argError("assertMultipleOf", 1, m, newNumError(ErrDivideByZero, "cannot be a multiple of zero"))
+	}
+	mr, err := f.toRat(m)
+	if err != nil {
+		return nil, argError("assertMultipleOf", 1, m, err)
+	}
+
+	_, vf, _, err := f.assertNumber("assertMultipleOf", 2, v, name)
+	if err != nil {
+		return nil, err
+	}
+	if !math.IsNaN(vf) && !math.IsInf(vf, 0) {
+		vr, err := f.toRat(v)
+		if err != nil {
+			return nil, argError("assertMultipleOf", 2, v, err)
+		}
+		if new(big.Rat).Quo(vr, mr).IsInt() {
+			return v, nil
+		}
+	}
+
+	return nil, assertionError("assertMultipleOf", 2, v, "%s must be a multiple of %v, got %v", name, m, v)
+}
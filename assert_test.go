@@ -0,0 +1,64 @@
+package sprigmath
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestAssert(t *testing.T) {
+	vars := map[string]interface{}{
+		"replicas": 3.0,
+		"half":     2.5,
+	}
+
+	for tpl, expected := range map[string]string{
+		`{{ assertRange "replicas" 1 100 .replicas }}`:           "3",
+		`{{ .replicas | assertRange "replicas" 1 100 | add 1 }}`: "4",
+		`{{ assertRange "" 1 3 3 }}`:                             "3",
+		`{{ assertPositive "ratio" .half }}`:                     "2.5",
+		`{{ .half | assertPositive "ratio" }}`:                   "2.5",
+		`{{ .replicas | assertInt "replicas" }}`:                 "3",
+		`{{ assertInt "" "42" }}`:                                "42",
+		`{{ assertMultipleOf "memory" 4 1024 }}`:                 "1024",
+		`{{ assertMultipleOf "" 0.1 0.3 }}`:                      "0.3",
+		`{{ .half | assertMultipleOf "half" 0.5 }}`:              "2.5",
+	} {
+		if err := runtv(tpl, expected, vars); err != nil {
+			t.Error(err)
+		}
+	}
+
+	for tpl, errstr := range map[string]string{
+		`{{ assertRange "replicas" 1 100 250 }}`: "assertRange[arg3]: replicas must be between 1 and 100, got 250",
+		`{{ assertRange "" 1 100 0 }}`:           "assertRange[arg3]: value must be between 1 and 100, got 0",
+		`{{ assertPositive "timeout" 0 }}`:       "assertPositive[arg1]: timeout must be positive, got 0",
+		`{{ .half | assertInt "replicas" }}`:     "assertInt[arg1]: replicas must be a whole number, got 2.5",
+		`{{ assertMultipleOf "memory" 4 10 }}`:   "assertMultipleOf[arg2]: memory must be a multiple of 4, got 10",
+		`{{ assertMultipleOf "" 0.25 (inf 1) }}`: "assertMultipleOf[arg2]: value must be a multiple of 0.25, got +Inf",
+	} {
+		_, err := runRaw(tpl, vars)
+		if !errors.Is(err, ErrAssertion) {
+			t.Errorf("%s: expected ErrAssertion, got %v", tpl, err)
+		} else if err = testError(errstr, err); err != nil {
+			t.Errorf("%s: %v", tpl, err)
+		}
+	}
+
+	for tpl, errstr := range map[string]string{
+		`{{ assertRange "replicas" 1 100 "many" }}`: "assertRange[arg3]: replicas must be a number, got many",
+		`{{ assertRange "" 1 "x" 0 }}`:              "assertRange[arg2]: x is not a float64 or int64",
+		`{{ assertPositive "" "x" }}`:               "assertPositive[arg1]: value must be a number, got x",
+		`{{ assertMultipleOf "" 0 3 }}`:             "assertMultipleOf[arg1]: cannot be a multiple of zero",
+	} {
+		if err := runerr(tpl, errstr); err != nil {
+			t.Error(err)
+		}
+	}
+
+	var argErr *ArgError
+	_, err := runRaw(`{{ 250 | assertRange "replicas" 1 100 }}`, nil)
+	if !errors.As(err, &argErr) || argErr.Func != "assertRange" || argErr.Index != 3 || argErr.Value != 250 {
+		t.Errorf("expected an ArgError for arg 3, got %#v", argErr)
+	}
+}
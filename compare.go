@@ -0,0 +1,222 @@
+package sprigmath
+
+import (
+	"math"
+	"math/big"
+)
+
+// compare converts two arguments of the named function with toNumber and
+// compares them, returning -1, 0 or +1. Integers are compared exactly, also
+// against floats, so 9007199254740993 is not equal to 9007199254740992.0.
+// ok is false when either value is NaN, which is unordered.
+func (f *funcs) compare(name string, i int, a, b interface{}) (c int, ok bool, err error) {
+	if hasQuantity([]interface{}{a, b}) {
+		return f.quantityCompare(name, i, a, b)
+	}
+	if isExact(a) || isExact(b) {
+		if c, ok := f.compareRat(a, b); ok {
+			return c, true, nil
+		}
+	}
+
+	ai, af, aFloat, err := f.numberArg(name, i, a)
+	if err != nil {
+		return 0, false, err
+	}
+	bi, bf, bFloat, err := f.numberArg(name, i+1, b)
+	if err != nil {
+		return 0, false, err
+	}
+
+	switch {
+	case math.IsNaN(af) || math.IsNaN(bf):
+		return 0, false, nil
+	case !aFloat && !bFloat:
+		switch {
+		case ai < bi:
+			return -1, true, nil
+		case ai > bi:
+			return 1, true, nil
+		}
+		return 0, true, nil
+	case !aFloat:
+		return new(big.Float).SetInt64(ai).Cmp(big.NewFloat(bf)), true, nil
+	case !bFloat:
+		return big.NewFloat(af).Cmp(new(big.Float).SetInt64(bi)), true, nil
+	}
+
+	switch {
+	case af < bf:
+		return -1, true, nil
+	case af > bf:
+		return 1, true, nil
+	}
+	return 0, true, nil
+}
+
+// isExact reports whether v is one of the types that hold a number exactly,
+// which must not be compared through a float64
+func isExact(v interface{}) bool {
+	switch v.(type) {
+	case Rational, Decimal, *big.Int, *big.Rat, *big.Float:
+		return true
+	}
+	return false
+}
+
+// compareRat compares a and b exactly. ok is false when either has no exact
+// value, such as NaN or an infinity, or is not a number, so that they are
+// compared or reported as usual.
+func (f *funcs) compareRat(a, b interface{}) (c int, ok bool) {
+	var rs [2]*big.Rat
+	for i, v := range []interface{}{a, b} {
+		r, err := f.toRat(v)
+		if err != nil {
+			return 0, false
+		}
+		rs[i] = r
+	}
+	return rs[0].Cmp(rs[1]), true
+}
+
+func (f *funcs) numEq(a interface{}, b interface{}) (bool, error) {
+	c, ok, err := f.compare("numEq", 0, a, b)
+	return ok && c == 0, err
+}
+
+func (f *funcs) numLt(a interface{}, b interface{}) (bool, error) {
+	c, ok, err := f.compare("numLt", 0, a, b)
+	return ok && c < 0, err
+}
+
+func (f *funcs) numLe(a interface{}, b interface{}) (bool, error) {
+	c, ok, err := f.compare("numLe", 0, a, b)
+	return ok && c <= 0, err
+}
+
+func (f *funcs) numGt(a interface{}, b interface{}) (bool, error) {
+	c, ok, err := f.compare("numGt", 0, a, b)
+	return ok && c > 0, err
+}
+
+func (f *funcs) numGe(a interface{}, b interface{}) (bool, error) {
+	c, ok, err := f.compare("numGe", 0, a, b)
+	return ok && c >= 0, err
+}
+
+// between reports whether lo <= x <= hi. x is last so that it can be piped in.
+func (f *funcs) between(lo interface{}, hi interface{}, x interface{}) (bool, error) {
+	// check every argument before comparing, so that errors are not skipped
+	for i, v := range []interface{}{lo, hi, x} {
+		if _, _, _, err := f.numberArg("between", i, v); err != nil {
+			return false, err
+		}
+	}
+
+	c, ok, err := f.compare("between", 0, lo, x)
+	if err != nil || !ok || c > 0 {
+		return false, err
+	}
+	c, ok, err = f.compare("between", 1, hi, x)
+	return ok && c >= 0, err
+}
+
+// tolerance is how close two floats must be to be approximately equal. They
+// are when they are within any of the tolerances that are set.
+type tolerance struct {
+	abs  float64
+	rel  float64
+	ulps int64
+}
+
+func (f *funcs) parseTolerance(name string, i int, v interface{}) (tolerance, error) {
+	var t tolerance
+
+	opts, ok := v.(map[string]interface{})
+	if !ok {
+		abs, err := f.toFloat64(v)
+		if err == nil && !(abs >= 0) {
+			err = newNumError(ErrDomain, "tolerance must not be negative, got %v", v)
+		}
+		if err != nil {
+			return t, argError(name, i, v, err)
+		}
+		t.abs = abs
+		return t, nil
+	}
+
+	for k, o := range opts {
+		var err error
+		switch k {
+		case "abs":
+			t.abs, err = f.toFloat64(o)
+		case "rel":
+			t.rel, err = f.toFloat64(o)
+		case "ulps":
+			t.ulps, err = f.toInt64(o)
+		default:
+			err = newNumError(ErrDomain, "unknown option %q", k)
+		}
+		if err == nil && !(t.abs >= 0 && t.rel >= 0 && t.ulps >= 0) {
+			err = newNumError(ErrDomain, "%s must not be negative, got %v", k, o)
+		}
+		if err != nil {
+			return t, argError(name, i, opts, err)
+		}
+	}
+	return t, nil
+}
+
+// ulpDistance is the number of floats between a and b, saturating at
+// math.MaxInt64
+func ulpDistance(a, b float64) int64 {
+	ordered := func(x float64) int64 {
+		i := int64(math.Float64bits(x))
+		if i < 0 {
+			// negative floats sort backwards, and -0 becomes 0
+			i = math.MinInt64 - i
+		}
+		return i
+	}
+
+	ia, ib := ordered(a), ordered(b)
+	if ia > ib {
+		ia, ib = ib, ia
+	}
+	if d := ib - ia; d >= 0 {
+		return d
+	}
+	return math.MaxInt64
+}
+
+// approxEq reports whether a and b are equal within a tolerance, which is
+// either an absolute difference or a dict with any of the keys "abs", "rel"
+// (relative to the larger magnitude) and "ulps" (units in the last place)
+func (f *funcs) approxEq(tol interface{}, a interface{}, b interface{}) (bool, error) {
+	t, err := f.parseTolerance("approxEq", 0, tol)
+	if err != nil {
+		return false, err
+	}
+
+	_, af, _, err := f.numberArg("approxEq", 1, a)
+	if err != nil {
+		return false, err
+	}
+	_, bf, _, err := f.numberArg("approxEq", 2, b)
+	if err != nil {
+		return false, err
+	}
+
+	switch {
+	case af == bf:
+		// also equal infinities
+		return true, nil
+	case math.IsNaN(af) || math.IsNaN(bf) || math.IsInf(af, 0) || math.IsInf(bf, 0):
+		return false, nil
+	}
+
+	diff := math.Abs(af - bf)
+	return diff <= t.abs ||
+		diff <= t.rel*math.Max(math.Abs(af), math.Abs(bf)) ||
+		ulpDistance(af, bf) <= t.ulps, nil
+}
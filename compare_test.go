@@ -0,0 +1,115 @@
+package sprigmath
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestCompare(t *testing.T) {
+	vars := map[string]interface{}{
+		"f":   3.0,
+		"big": 9007199254740993,
+		"nan": math.NaN(),
+
+		"third":   NewRational(big.NewRat(1, 3)),
+		"tenth":   NewDecimal(big.NewRat(1, 10), 1, RoundHalfUp),
+		"bigInt":  new(big.Int).Add(big.NewInt(math.MaxInt64), big.NewInt(2)),
+		"bigRat":  new(big.Rat).SetFrac(new(big.Int).Lsh(big.NewInt(1), 80), new(big.Int).Add(new(big.Int).Lsh(big.NewInt(1), 80), big.NewInt(1))),
+		"float1":  1.0,
+		"maxInt1": int64(math.MaxInt64),
+	}
+
+	for tpl, expected := range map[string]string{
+		`{{ numEq .f 3 }}`:                    "true",
+		`{{ numEq "3" 3.0 }}`:                 "true",
+		`{{ numEq .big 9007199254740992.0 }}`: "false",
+		`{{ numEq .nan .nan }}`:               "false",
+		`{{ numLt 2 2.5 }}`:                   "true",
+		`{{ numLt .nan 1 }}`:                  "false",
+		`{{ numLe 3 .f }}`:                    "true",
+		`{{ numGt "10" 9.99 }}`:               "true",
+		`{{ numGe 1 2 }}`:                     "false",
+		`{{ .f | between 1 3 }}`:              "true",
+		`{{ 3.5 | between 1 3 }}`:             "false",
+		`{{ 0 | between 1 3 }}`:               "false",
+		`{{ .nan | between 1 3 }}`:            "false",
+
+		// exact types are compared exactly rather than as float64
+		`{{ numLt .bigRat .float1 }}`:             "true",
+		`{{ numEq .bigRat 1 }}`:                   "false",
+		`{{ numGt .bigInt .maxInt1 }}`:            "true",
+		`{{ numEq .tenth "0.1" }}`:                "true",
+		`{{ numEq .tenth 0.1 }}`:                  "true",
+		`{{ numLt .third 0.3333333333333333 }}`:   "false",
+		`{{ numGt .third "0.3333333333333333" }}`: "true",
+		`{{ numLt .third (inf 1) }}`:              "true",
+	} {
+		if err := runtv(tpl, expected, vars); err != nil {
+			t.Error(err)
+		}
+	}
+
+	for tpl, errstr := range map[string]string{
+		`{{ numEq 1 "x" }}`:       `numEq[arg1]: x is not a float64 or int64`,
+		`{{ numLt "x" 1 }}`:       `numLt[arg0]: x is not a float64 or int64`,
+		`{{ between 3 "x" 1 }}`:   `between[arg1]: x is not a float64 or int64`,
+		`{{ "x" | between 1 3 }}`: `between[arg2]: x is not a float64 or int64`,
+	} {
+		_, err := runRaw(tpl, nil)
+		if !errors.Is(err, ErrNotNumber) {
+			t.Errorf("%s: expected ErrNotNumber, got %v", tpl, err)
+		} else if err = testError(errstr, err); err != nil {
+			t.Errorf("%s: %v", tpl, err)
+		}
+	}
+}
+
+func TestApproxEq(t *testing.T) {
+	for tpl, expected := range map[string]string{
+		`{{ approxEq 0.01 1.0 1.005 }}`:                          "true",
+		`{{ approxEq 0.01 1.0 1.02 }}`:                           "false",
+		`{{ approxEq 0 3 3.0 }}`:                                 "true",
+		`{{ approxEq (dict "rel" 0.01) 1000 1009 }}`:             "true",
+		`{{ approxEq (dict "rel" 0.01) 1000 1011 }}`:             "false",
+		`{{ approxEq (dict "ulps" 1) 0.30000000000000004 0.3 }}`: "true",
+		`{{ approxEq (dict "ulps" 1) 0.3 0.3000000000000001 }}`:  "false",
+		`{{ approxEq (dict "abs" 1 "rel" 0) 1 2 }}`:              "true",
+		`{{ approxEq 1 (inf 1) (inf 1) }}`:                       "true",
+		`{{ approxEq 1e300 (inf 1) 1 }}`:                         "false",
+	} {
+		if err := runt(tpl, expected); err != nil {
+			t.Error(err)
+		}
+	}
+
+	for tpl, errstr := range map[string]string{
+		`{{ approxEq -1 1 1 }}`:                "approxEq[arg0]: tolerance must not be negative, got -1",
+		`{{ approxEq (dict "ulp" 1) 1 1 }}`:    `approxEq[arg0]: unknown option "ulp"`,
+		`{{ approxEq (dict "rel" -0.1) 1 1 }}`: "approxEq[arg0]: rel must not be negative, got -0.1",
+		`{{ approxEq 0.1 1 "x" }}`:             `approxEq[arg2]: x is not a float64 or int64`,
+	} {
+		if err := runerr(tpl, errstr); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestUlpDistance(t *testing.T) {
+	for _, tc := range []struct {
+		a, b     float64
+		expected int64
+	}{
+		{1, 1, 0},
+		{0, math.Copysign(0, -1), 0},
+		{1, math.Nextafter(1, 2), 1},
+		{-math.SmallestNonzeroFloat64, math.SmallestNonzeroFloat64, 2},
+		{-math.MaxFloat64, math.MaxFloat64, math.MaxInt64},
+	} {
+		if d := ulpDistance(tc.a, tc.b); d != tc.expected {
+			t.Errorf("ulpDistance(%v, %v): expected %d, got %d", tc.a, tc.b, tc.expected, d)
+		}
+	}
+}
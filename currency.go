@@ -0,0 +1,180 @@
+package sprigmath
+
+import (
+	"strings"
+)
+
+// currency describes an ISO 4217 currency
+type currency struct {
+	minor  int    // number of digits after the point
+	symbol string // symbol used when formatting
+}
+
+// currencies is the ISO 4217 table of the currencies in common use
+var currencies = map[string]currency{
+	"AED": {2, "AED"},
+	"ARS": {2, "ARS"},
+	"AUD": {2, "A$"},
+	"BHD": {3, "BHD"},
+	"BRL": {2, "R$"},
+	"CAD": {2, "CA$"},
+	"CHF": {2, "CHF"},
+	"CLP": {0, "CLP"},
+	"CNY": {2, "CN\u00a5"},
+	"COP": {2, "COP"},
+	"CZK": {2, "CZK"},
+	"DKK": {2, "DKK"},
+	"EGP": {2, "EGP"},
+	"EUR": {2, "\u20ac"},
+	"GBP": {2, "\u00a3"},
+	"HKD": {2, "HK$"},
+	"HUF": {2, "HUF"},
+	"IDR": {2, "IDR"},
+	"ILS": {2, "\u20aa"},
+	"INR": {2, "\u20b9"},
+	"IQD": {3, "IQD"},
+	"ISK": {0, "ISK"},
+	"JOD": {3, "JOD"},
+	"JPY": {0, "\u00a5"},
+	"KRW": {0, "\u20a9"},
+	"KWD": {3, "KWD"},
+	"LYD": {3, "LYD"},
+	"MXN": {2, "MX$"},
+	"MYR": {2, "MYR"},
+	"NGN": {2, "NGN"},
+	"NOK": {2, "NOK"},
+	"NZD": {2, "NZ$"},
+	"OMR": {3, "OMR"},
+	"PHP": {2, "\u20b1"},
+	"PKR": {2, "PKR"},
+	"PLN": {2, "PLN"},
+	"RON": {2, "RON"},
+	"RUB": {2, "RUB"},
+	"SAR": {2, "SAR"},
+	"SEK": {2, "SEK"},
+	"SGD": {2, "SGD"},
+	"THB": {2, "THB"},
+	"TND": {3, "TND"},
+	"TRY": {2, "TRY"},
+	"TWD": {2, "NT$"},
+	"UAH": {2, "UAH"},
+	"UGX": {0, "UGX"},
+	"USD": {2, "$"},
+	"VND": {0, "\u20ab"},
+	"XAF": {0, "FCFA"},
+	"XOF": {0, "F\u202fCFA"},
+	"ZAR": {2, "ZAR"},
+}
+
+// currencyPattern describes where a locale puts the currency symbol
+type currencyPattern struct {
+	prefix bool
+	space  string
+}
+
+var (
+	patternPrefix      = currencyPattern{true, ""}
+	patternPrefixSpace = currencyPattern{true, "\u00a0"}
+	patternSuffixSpace = currencyPattern{false, "\u00a0"}
+)
+
+// currencyPatterns maps lower case BCP 47 tags to where the currency symbol
+// goes. Tags that are not listed fall back to their language, and then to a
+// prefix without a space.
+var currencyPatterns = map[string]currencyPattern{
+	"de":    patternSuffixSpace,
+	"de-at": patternPrefixSpace,
+	"de-ch": patternPrefixSpace,
+	"it":    patternSuffixSpace,
+	"it-ch": patternPrefixSpace,
+	"fr":    patternSuffixSpace,
+	"es":    patternSuffixSpace,
+	"es-mx": patternPrefix,
+	"es-us": patternPrefix,
+	"pt":    patternPrefixSpace,
+	"pt-pt": patternSuffixSpace,
+	"nl":    patternPrefixSpace,
+	"da":    patternSuffixSpace,
+	"sv":    patternSuffixSpace,
+	"nb":    patternSuffixSpace,
+	"no":    patternSuffixSpace,
+	"fi":    patternSuffixSpace,
+	"pl":    patternSuffixSpace,
+	"cs":    patternSuffixSpace,
+	"ru":    patternSuffixSpace,
+	"uk":    patternSuffixSpace,
+	"vi":    patternSuffixSpace,
+	"id":    patternPrefix,
+	"tr":    patternPrefix,
+}
+
+func lookupCurrencyPattern(tag string) currencyPattern {
+	key := strings.ToLower(strings.Replace(strings.TrimSpace(tag), "_", "-", -1))
+	if p, ok := currencyPatterns[key]; ok {
+		return p
+	}
+	if i := strings.IndexByte(key, '-'); i != -1 {
+		if p, ok := currencyPatterns[key[:i]]; ok {
+			return p
+		}
+	}
+	return patternPrefix
+}
+
+func lookupCurrency(code string) (currency, error) {
+	if c, ok := currencies[strings.ToUpper(strings.TrimSpace(code))]; ok {
+		return c, nil
+	}
+	return currency{}, newNumError(ErrDomain, "unknown currency %q", code)
+}
+
+// roundCurrency rounds an amount to the minor units of an ISO 4217 currency,
+// using Options.Rounding
+func (f *funcs) roundCurrency(code string, amount interface{}) (Decimal, error) {
+	c, err := lookupCurrency(code)
+	if err != nil {
+		return Decimal{}, argError("roundCurrency", 0, code, err)
+	}
+
+	r, err := f.toRat(amount)
+	if err != nil {
+		return Decimal{}, argError("roundCurrency", 1, amount, err)
+	}
+
+	return NewDecimal(r, c.minor, f.Rounding), nil
+}
+
+// formatCurrency formats an amount in an ISO 4217 currency as written in a
+// locale, rounding it to the currency's minor units using Options.Rounding
+func (f *funcs) formatCurrency(code string, locale string, amount interface{}) (string, error) {
+	c, err := lookupCurrency(code)
+	if err != nil {
+		return "", argError("formatCurrency", 0, code, err)
+	}
+
+	sym, err := lookupLocale(locale)
+	if err != nil {
+		return "", argError("formatCurrency", 1, locale, err)
+	}
+
+	r, err := f.toRat(amount)
+	if err != nil {
+		return "", argError("formatCurrency", 2, amount, err)
+	}
+
+	s := localize(NewDecimal(r, c.minor, f.Rounding).String(), sym, true, 0)
+
+	neg := strings.HasPrefix(s, sym.minus)
+	s = strings.TrimPrefix(s, sym.minus)
+
+	if p := lookupCurrencyPattern(locale); p.prefix {
+		s = c.symbol + p.space + s
+	} else {
+		s = s + p.space + c.symbol
+	}
+
+	if neg {
+		s = sym.minus + s
+	}
+	return s, nil
+}
@@ -0,0 +1,43 @@
+package sprigmath
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCurrency(t *testing.T) {
+	vars := map[string]interface{}{"amount": json.Number("1234.565")}
+
+	for tpl, expected := range map[string]string{
+		`{{ roundCurrency "JPY" 1234.5 }}`:                "1235",
+		`{{ roundCurrency "USD" 2.675 }}`:                 "2.68",
+		`{{ roundCurrency "KWD" 1 }}`:                     "1.000",
+		`{{ roundCurrency "usd" .amount }}`:               "1234.57",
+		`{{ roundCurrency "USD" 0.1 | add 0.2 }}`:         "0.30",
+		`{{ formatCurrency "USD" "en-US" 1234.5 }}`:       "$1,234.50",
+		`{{ formatCurrency "USD" "en-US" -1234.5 }}`:      "-$1,234.50",
+		`{{ formatCurrency "EUR" "de-DE" 1234.5 }}`:       "1.234,50\u00a0\u20ac",
+		`{{ formatCurrency "EUR" "nl" 1234.5 }}`:          "\u20ac\u00a01.234,50",
+		`{{ formatCurrency "JPY" "ja-JP" 1234.5 }}`:       "\u00a51,235",
+		`{{ formatCurrency "INR" "en-IN" 1234567 }}`:      "\u20b912,34,567.00",
+		`{{ formatCurrency "KWD" "en" .amount }}`:         "KWD1,234.565",
+		`{{ formatCurrency "SEK" "sv" -5 }}`:              "\u22125,00\u00a0SEK",
+		`{{ roundCurrency "EUR" 3 | formatNumber "de" }}`: "3,00",
+	} {
+		if out, err := runRaw(tpl, vars); err != nil || out != expected {
+			t.Errorf("%s: expected %q, got %q %v", tpl, expected, out, err)
+		}
+	}
+
+	opts := Options{Rounding: RoundHalfEven}
+	if out, err := runOpts(opts, `{{ roundCurrency "USD" 0.125 }} {{ formatCurrency "USD" "en" 0.135 }}`, nil); err != nil || out != "0.12 $0.14" {
+		t.Errorf("Expected '0.12 $0.14', got %q %v", out, err)
+	}
+
+	if err := runerr(`{{ roundCurrency "XYZ" 1 }}`, `roundCurrency[arg0]: unknown currency "XYZ"`); err != nil {
+		t.Error(err)
+	}
+	if err := runerr(`{{ formatCurrency "USD" "en" "bob" }}`, `formatCurrency[arg2]: bob is not a float64 or int64`); err != nil {
+		t.Error(err)
+	}
+}
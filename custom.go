@@ -0,0 +1,180 @@
+package sprigmath
+
+import (
+	"math"
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// RegisterUnary adds a function of one float64 to the FuncMap of m. Its
+// argument is converted like those of sqrt, and in strict mode a NaN or
+// infinite result is an error.
+func (m *Math) RegisterUnary(name string, fn func(float64) float64) {
+	m.custom[name] = m.mustWrap(name, fn)
+}
+
+// RegisterBinary adds a function of two float64 to the FuncMap of m, like
+// RegisterUnary
+func (m *Math) RegisterBinary(name string, fn func(float64, float64) float64) {
+	m.custom[name] = m.mustWrap(name, fn)
+}
+
+// Register wraps fn with Wrap and adds it to the FuncMap of m
+func (m *Math) Register(name string, fn interface{}) error {
+	w, err := m.Wrap(name, fn)
+	if err != nil {
+		return err
+	}
+	m.custom[name] = w
+	return nil
+}
+
+func (m *Math) mustWrap(name string, fn interface{}) interface{} {
+	w, err := m.Wrap(name, fn)
+	if err != nil {
+		panic(err)
+	}
+	return w
+}
+
+// Wrap adapts a Go function into a template function that accepts any value
+// for its parameters, converting them like the built in functions do. Float
+// parameters accept anything that float64 does, and integer parameters accept
+// whole numbers in their range, failing rather than truncating. interface{}
+// parameters are passed as-is. fn may return an error as its last result.
+//
+// Errors, including those returned by fn, are labelled with name and the
+// index of the argument where there is one, and in strict mode a NaN or infinite float64 result is an error, as with sqrt.
+func (m *Math) Wrap(name string, fn interface{}) (interface{}, error) {
+	fv := reflect.ValueOf(fn)
+	if fv.Kind() != reflect.Func || fv.IsNil() {
+		return nil, errors.Errorf("%s: %T is not a function", name, fn)
+	}
+	ft := fv.Type()
+
+	in := make([]reflect.Type, ft.NumIn())
+	for i := range in {
+		t := ft.In(i)
+		if ft.IsVariadic() && i == len(in)-1 {
+			t = t.Elem()
+		}
+		if !wrappable(t) {
+			return nil, errors.Errorf("%s: cannot convert arguments to %s", name, t)
+		}
+		in[i] = interfaceType
+	}
+	if ft.IsVariadic() {
+		in[len(in)-1] = reflect.SliceOf(interfaceType)
+	}
+
+	var out []reflect.Type
+	hasError := ft.NumOut() > 0 && ft.Out(ft.NumOut()-1) == errorType
+	switch {
+	case ft.NumOut() == 0:
+		return nil, errors.Errorf("%s: function must return a value", name)
+	case ft.NumOut() == 1 && !hasError, ft.NumOut() == 2 && hasError:
+		out = []reflect.Type{ft.Out(0), errorType}
+	default:
+		return nil, errors.Errorf("%s: function must return a value and an optional error", name)
+	}
+
+	f := m.f
+	wt := reflect.FuncOf(in, out, ft.IsVariadic())
+	return reflect.MakeFunc(wt, func(args []reflect.Value) []reflect.Value {
+		fail := func(err error) []reflect.Value {
+			return []reflect.Value{reflect.Zero(out[0]), reflect.ValueOf(&err).Elem()}
+		}
+
+		values := args
+		if ft.IsVariadic() {
+			last := args[len(args)-1]
+			values = append([]reflect.Value{}, args[:len(args)-1]...)
+			for i := 0; i < last.Len(); i++ {
+				values = append(values, last.Index(i))
+			}
+		}
+
+		var floats []float64
+		call := make([]reflect.Value, len(values))
+		raw := make([]interface{}, len(values))
+		for i, v := range values {
+			var t reflect.Type
+			if ft.IsVariadic() && i >= ft.NumIn()-1 {
+				t = ft.In(ft.NumIn() - 1).Elem()
+			} else {
+				t = ft.In(i)
+			}
+			arg := v.Interface()
+			raw[i] = arg
+
+			c, err := f.convertArg(arg, t)
+			if err != nil {
+				return fail(argError(name, i, arg, err))
+			}
+			if c.Kind() == reflect.Float32 || c.Kind() == reflect.Float64 {
+				floats = append(floats, c.Float())
+			}
+			call[i] = c
+		}
+
+		results := fv.Call(call)
+		if hasError && !results[1].IsNil() {
+			return fail(argError(name, -1, raw, results[1].Interface().(error)))
+		}
+
+		r := results[0]
+		if r.Kind() == reflect.Float64 {
+			if _, err := f.checkFloat(name, r.Float(), ErrOverflow, floats...); err != nil {
+				return fail(err)
+			}
+		}
+		return []reflect.Value{r, reflect.Zero(errorType)}
+	}).Interface(), nil
+}
+
+// wrappable reports whether Wrap can convert arguments to t
+func wrappable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Interface:
+		return t.NumMethod() == 0
+	}
+	return false
+}
+
+// convertArg converts a template argument to the parameter type t of a
+// wrapped function
+func (f *funcs) convertArg(v interface{}, t reflect.Type) (reflect.Value, error) {
+	switch t.Kind() {
+	case reflect.Interface:
+		if v == nil {
+			return reflect.Zero(t), nil
+		}
+		return reflect.ValueOf(v), nil
+
+	case reflect.Float32, reflect.Float64:
+		fv, err := f.toFloat64(v)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(fv).Convert(t), nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		max := uint64(1)<<uint(t.Bits()-1) - 1
+		n, err := f.toIntRange(v, t.Kind().String(), -int64(max)-1, max)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(n.Int64()).Convert(t), nil
+	}
+
+	n, err := f.toIntRange(v, t.Kind().String(), 0, math.MaxUint64>>uint(64-t.Bits()))
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return reflect.ValueOf(n.Uint64()).Convert(t), nil
+}
@@ -0,0 +1,124 @@
+package sprigmath
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/pkg/errors"
+)
+
+func runMath(m *Math, tpl string, vars interface{}) (string, error) {
+	t := template.Must(template.New("test").Funcs(m.FuncMap()).Parse(tpl))
+	var b bytes.Buffer
+	err := t.Execute(&b, vars)
+	return b.String(), err
+}
+
+func TestRegister(t *testing.T) {
+	m := NewMath(Options{})
+	m.RegisterUnary("sigmoid", func(x float64) float64 { return 1 / (1 + math.Exp(-x)) })
+	m.RegisterBinary("logn", func(b, x float64) float64 { return math.Log(x) / math.Log(b) })
+	if err := m.Register("clamp8", func(lo, hi int8, x float64) float64 {
+		return math.Max(float64(lo), math.Min(float64(hi), x))
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Register("mean", func(xs ...float64) (float64, error) {
+		if len(xs) == 0 {
+			return 0, errors.New("no values")
+		}
+		sum := 0.0
+		for _, x := range xs {
+			sum += x
+		}
+		return sum / float64(len(xs)), nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Register("describe", func(label interface{}, n uint16) string {
+		return strings.Repeat("*", int(n))
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	vars := map[string]interface{}{"x": 0.0, "n": 3.0}
+	for tpl, expected := range map[string]string{
+		`{{ sigmoid .x }}`:          "0.5",
+		`{{ sigmoid "0" }}`:         "0.5",
+		`{{ 8 | logn 2 }}`:          "3",
+		`{{ clamp8 1 10 42 }}`:      "10",
+		`{{ mean 1 2 "3" 4.5 }}`:    "2.625",
+		`{{ describe nil .n }}`:     "***",
+		`{{ try "sigmoid" "x" 0 }}`: "0",
+		`{{ try "mean" 0 }}`:        "0",
+		`{{ sigmoid .x | mul 4 }}`:  "2",
+	} {
+		if out, err := runMath(m, tpl, vars); err != nil || out != expected {
+			t.Errorf("%s: expected %q, got %q %v", tpl, expected, out, err)
+		}
+	}
+
+	for tpl, errstr := range map[string]string{
+		`{{ sigmoid "x" }}`:      "sigmoid[arg0]: cannot convert x to float64",
+		`{{ logn 2 .missing }}`:  "logn[arg1]: missing value",
+		`{{ clamp8 1 300 42 }}`:  "clamp8[arg1]: 300 overflows int8",
+		`{{ clamp8 1.5 10 42 }}`: "clamp8[arg0]: 1.5 is not a whole number",
+		`{{ describe 1 -1 }}`:    "describe[arg1]: -1 overflows uint16",
+		`{{ mean }}`:             "mean: no values",
+	} {
+		_, err := runMath(m, tpl, vars)
+		if err = testError(errstr, err); err != nil {
+			t.Errorf("%s: %v", tpl, err)
+		}
+	}
+
+	// functions are scoped to the Math they were registered with
+	if _, ok := FuncMap(Options{})["sigmoid"]; ok {
+		t.Error("sigmoid should not be in another FuncMap")
+	}
+}
+
+func TestRegisterOptions(t *testing.T) {
+	m := NewMath(Options{Strict: true, FloatFormat: FormatFixed, Decimals: 2, Missing: MissingZero})
+	m.RegisterUnary("recip", func(x float64) float64 { return 1 / x })
+
+	for tpl, expected := range map[string]string{
+		`{{ recip 3 }}`:       "0.33",
+		`{{ recip (inf 1) }}`: "0.00",
+	} {
+		if out, err := runMath(m, tpl, nil); err != nil || out != expected {
+			t.Errorf("%s: expected %q, got %q %v", tpl, expected, out, err)
+		}
+	}
+
+	_, err := runMath(m, `{{ recip .missing }}`, nil)
+	if !errors.Is(err, ErrOverflow) {
+		t.Errorf("expected ErrOverflow for 1/0 in strict mode, got %v", err)
+	}
+}
+
+func TestWrapErrors(t *testing.T) {
+	m := NewMath(Options{})
+	for _, fn := range []interface{}{
+		42,
+		func(s string) float64 { return 0 },
+		func(x float64) {},
+		func(x float64) (float64, float64) { return 0, 0 },
+		func(x float64) (float64, error, error) { return 0, nil, nil },
+	} {
+		if _, err := m.Wrap("bad", fn); err == nil {
+			t.Errorf("expected an error wrapping %T", fn)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected RegisterUnary to panic for a nil function")
+		}
+	}()
+	var nilFn func(float64) float64
+	m.RegisterUnary("nil", nilFn)
+}
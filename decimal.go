@@ -0,0 +1,212 @@
+package sprigmath
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Decimal is an exact decimal number with a fixed number of digits after the
+// point. It is returned by functions that must not lose precision, such as
+// roundCurrency. It renders as plain digits, and every function accepts it as
+// a number.
+type Decimal struct {
+	rat   *big.Rat
+	scale int
+}
+
+// NewDecimal returns r with scale digits after the point, rounded using mode
+func NewDecimal(r *big.Rat, scale int, mode RoundingMode) Decimal {
+	return Decimal{roundRat(r, scale, mode), scale}
+}
+
+func (d Decimal) value() *big.Rat {
+	if d.rat == nil {
+		return new(big.Rat)
+	}
+	return d.rat
+}
+
+// Rat returns the exact value of d
+func (d Decimal) Rat() *big.Rat {
+	return new(big.Rat).Set(d.value())
+}
+
+// Scale returns the number of digits after the point
+func (d Decimal) Scale() int {
+	return d.scale
+}
+
+// Float64 implements Float64er
+func (d Decimal) Float64() (float64, error) {
+	fv, _ := d.value().Float64()
+	return fv, nil
+}
+
+func (d Decimal) String() string {
+	return d.value().FloatString(d.scale)
+}
+
+// MarshalText renders d as plain digits
+func (d Decimal) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// hasDecimal reports whether any of args is a Decimal, so that sums and
+// products are calculated exactly
+func hasDecimal(args []interface{}) bool {
+	for _, arg := range args {
+		if _, ok := arg.(Decimal); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// decimalFold combines args from left to right with op, exactly. The result
+// has the largest number of digits after the point of any argument, or their
+// sum for a product, so that it is exact.
+func (f *funcs) decimalFold(name string, args []interface{}, op func(z, x, y *big.Rat) *big.Rat, product bool) (Decimal, error) {
+	var acc *big.Rat
+	scale := 0
+	for i, arg := range args {
+		lit, err := f.literal(arg)
+		if err != nil {
+			return Decimal{}, argError(name, i, arg, err)
+		}
+		r, err := toRat(lit)
+		if err != nil {
+			return Decimal{}, argError(name, i, arg, err)
+		}
+		s, _ := decimalScale(lit, r)
+		if product {
+			scale += s
+		} else if s > scale {
+			scale = s
+		}
+
+		if acc == nil {
+			acc = r
+			continue
+		}
+		op(acc, acc, r)
+	}
+	return Decimal{acc, scale}, nil
+}
+
+// RoundingMode selects how a value is rounded to a number of decimals
+type RoundingMode int
+
+const (
+	// RoundHalfUp rounds to the nearest value, and ties away from zero.
+	// This is what round does.
+	RoundHalfUp RoundingMode = iota
+
+	// RoundHalfEven rounds to the nearest value, and ties to an even digit
+	RoundHalfEven
+
+	// RoundUp rounds away from zero
+	RoundUp
+
+	// RoundDown rounds towards zero, which is what trunc does
+	RoundDown
+
+	// RoundCeiling rounds towards positive infinity, which is what ceil does
+	RoundCeiling
+
+	// RoundFloor rounds towards negative infinity, which is what floor does
+	RoundFloor
+)
+
+// roundRat rounds r to the given number of digits after the point
+func roundRat(r *big.Rat, places int, mode RoundingMode) *big.Rat {
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(places)), nil)
+	x := new(big.Rat).Mul(r, new(big.Rat).SetInt(scale))
+
+	q, rem := new(big.Int).QuoRem(x.Num(), x.Denom(), new(big.Int))
+	if rem.Sign() != 0 {
+		sign := int64(x.Sign())
+		// compare the remainder with half of the denominator
+		half := new(big.Int).Abs(rem)
+		half.Mul(half, big.NewInt(2))
+		cmp := half.Cmp(x.Denom())
+
+		away := false
+		switch mode {
+		case RoundHalfUp:
+			away = cmp >= 0
+		case RoundHalfEven:
+			away = cmp > 0 || (cmp == 0 && q.Bit(0) == 1)
+		case RoundUp:
+			away = true
+		case RoundCeiling:
+			away = sign > 0
+		case RoundFloor:
+			away = sign < 0
+		}
+
+		if away {
+			q.Add(q, big.NewInt(sign))
+		}
+	}
+
+	return new(big.Rat).SetFrac(q, scale)
+}
+
+// toRat converts v to an exact rational like the package level toRat, reading
+// strings as toNumber does, with Locale, SIPrefixes and ParseNaN
+func (f *funcs) toRat(v interface{}) (*big.Rat, error) {
+	v, err := f.literal(v)
+	if err != nil {
+		return nil, err
+	}
+	return toRat(v)
+}
+
+// toRat converts v to an exact rational. Decimal strings, json.Number, math/big
+// values and Decimal are converted exactly, and a float64 is converted from
+// its shortest decimal representation, so 0.1 is exactly one tenth.
+func toRat(v interface{}) (*big.Rat, error) {
+	n, err := toNumber(v)
+	if err != nil {
+		return nil, err
+	}
+
+	switch x := v.(type) {
+	case Decimal:
+		return x.Rat(), nil
+	case Rational:
+		return x.Rat(), nil
+	case *big.Int:
+		return new(big.Rat).SetInt(x), nil
+	case *big.Rat:
+		return new(big.Rat).Set(x), nil
+	case *big.Float:
+		if !x.IsInf() {
+			r, _ := x.Rat(nil)
+			return r, nil
+		}
+	case json.Number:
+		if r, ok := new(big.Rat).SetString(string(x)); ok {
+			return r, nil
+		}
+	case string:
+		if r, ok := new(big.Rat).SetString(strings.TrimSpace(x)); ok {
+			return r, nil
+		}
+	}
+
+	switch x := n.(type) {
+	case int64:
+		return new(big.Rat).SetInt64(x), nil
+	default:
+		fv := x.(float64)
+		if math.IsNaN(fv) || math.IsInf(fv, 0) {
+			return nil, newNumError(ErrDomain, "%v has no exact value", v)
+		}
+		r, _ := new(big.Rat).SetString(strconv.FormatFloat(fv, 'g', -1, 64))
+		return r, nil
+	}
+}
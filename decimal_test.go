@@ -0,0 +1,93 @@
+package sprigmath
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestRoundRat(t *testing.T) {
+	for _, tc := range []struct {
+		r        *big.Rat
+		mode     RoundingMode
+		expected string
+	}{
+		{big.NewRat(25, 10), RoundHalfUp, "3"},
+		{big.NewRat(-25, 10), RoundHalfUp, "-3"},
+		{big.NewRat(25, 10), RoundHalfEven, "2"},
+		{big.NewRat(35, 10), RoundHalfEven, "4"},
+		{big.NewRat(26, 10), RoundHalfEven, "3"},
+		{big.NewRat(21, 10), RoundUp, "3"},
+		{big.NewRat(-29, 10), RoundDown, "-2"},
+		{big.NewRat(-21, 10), RoundCeiling, "-2"},
+		{big.NewRat(-21, 10), RoundFloor, "-3"},
+		{big.NewRat(2, 1), RoundUp, "2"},
+	} {
+		if s := roundRat(tc.r, 0, tc.mode).FloatString(0); s != tc.expected {
+			t.Errorf("roundRat(%v, %v): expected %s, got %s", tc.r, tc.mode, tc.expected, s)
+		}
+	}
+}
+
+func TestToRat(t *testing.T) {
+	for _, tc := range []struct {
+		in       interface{}
+		expected string
+	}{
+		{0.1, "1/10"},
+		{int8(-3), "-3/1"},
+		{"2.50", "5/2"},
+		{json.Number("1e-3"), "1/1000"},
+		{big.NewRat(1, 3), "1/3"},
+		{NewDecimal(big.NewRat(1, 3), 2, RoundHalfUp), "33/100"},
+		{Decimal{}, "0/1"},
+	} {
+		if r, err := toRat(tc.in); err != nil || r.String() != tc.expected {
+			t.Errorf("toRat(%v): expected %s, got %v %v", tc.in, tc.expected, r, err)
+		}
+	}
+
+	if _, err := toRat("bob"); err == nil {
+		t.Error("Expected an error for bob")
+	}
+}
+
+func TestDecimalZeroValue(t *testing.T) {
+	var d Decimal
+	if s := d.String(); s != "0" {
+		t.Errorf("expected 0, got %q", s)
+	}
+	if fv, err := d.Float64(); err != nil || fv != 0 {
+		t.Errorf("expected 0, got %v %v", fv, err)
+	}
+	if r := d.Rat(); r.Sign() != 0 {
+		t.Errorf("expected 0, got %v", r)
+	}
+	if err := runtv(`{{ add .d 1 }}`, "1", map[string]interface{}{"d": d}); err != nil {
+		t.Error(err)
+	}
+
+	var bf *big.Float
+	if _, err := decimalString(bf, 2); !errors.Is(err, ErrMissing) {
+		t.Errorf("expected ErrMissing for a nil *big.Float, got %v", err)
+	}
+}
+
+func TestDecimalArithmetic(t *testing.T) {
+	for tpl, expected := range map[string]string{
+		`{{ add (roundCurrency "USD" 0.1) (roundCurrency "USD" 0.2) }}`:                  "0.30",
+		`{{ sub (roundCurrency "USD" 1.10) (roundCurrency "USD" 1) }}`:                   "0.10",
+		`{{ add (roundCurrency "USD" 19.99) 0.005 }}`:                                    "19.995",
+		`{{ add (roundCurrency "JPY" 100) 1 "2" }}`:                                      "103",
+		`{{ mul (roundCurrency "USD" 19.99) 3 }}`:                                        "59.97",
+		`{{ mul (roundCurrency "USD" 0.1) "0.5" }}`:                                      "0.050",
+		`{{ $s := 0 }}{{ range allocate "0.30" 3 }}{{ $s = add $s . }}{{ end }}{{ $s }}`: "0.30",
+		`{{ add (roundCurrency "USD" 0.1) 0.2 | printf "%T" }}`:                          "sprigmath.Decimal",
+	} {
+		if err := runt(tpl, expected); err != nil {
+			t.Error(err)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package sprigmath
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// Sentinel errors that describe why a function failed. Use errors.Is to test
+// for them; they are wrapped by conversion errors and by ArgError.
+var (
+	// ErrNotNumber is returned when a value cannot be converted to a number
+	ErrNotNumber = errors.New("not a number")
+
+	// ErrOverflow is returned when a value does not fit in the requested type
+	ErrOverflow = errors.New("overflow")
+
+	// ErrDivideByZero is returned when dividing by zero has no sensible result
+	ErrDivideByZero = errors.New("division by zero")
+
+	// ErrDomain is returned when an argument is outside of a function's domain
+	ErrDomain = errors.New("argument out of domain")
+
+	// ErrMissing is returned when a value is nil or missing, such as a map
+	// key that is not set, and Options.Missing is MissingError
+	ErrMissing = errors.New("missing value")
+
+	// ErrAssertion is returned when a value fails one of the assert functions
+	ErrAssertion = errors.New("assertion failed")
+)
+
+// ArgError describes an argument that a template function could not use.
+// Use errors.As to retrieve it from an error returned by template execution.
+type ArgError struct {
+	Func  string      // name of the template function
+	Index int         // zero-based index of the argument, or -1 if not specific to one
+	Value interface{} // the offending value
+	Cause error       // the underlying error, which wraps one of the Err* sentinels
+}
+
+func (e *ArgError) Error() string {
+	if e.Index < 0 {
+		return fmt.Sprintf("%s: %v", e.Func, e.Cause)
+	}
+	return fmt.Sprintf("%s[arg%d]: %v", e.Func, e.Index, e.Cause)
+}
+
+func (e *ArgError) Unwrap() error {
+	return e.Cause
+}
+
+func argError(name string, i int, v interface{}, err error) error {
+	return &ArgError{Func: name, Index: i, Value: v, Cause: err}
+}
+
+// numError is returned by the conversion functions; it keeps the historical
+// message while still matching one of the sentinels with errors.Is
+type numError struct {
+	msg string
+	err error
+}
+
+func (e *numError) Error() string {
+	return e.msg
+}
+
+func (e *numError) Unwrap() error {
+	return e.err
+}
+
+// errMissing is the conversion error for a missing value
+var errMissing = newNumError(ErrMissing, "missing value")
+
+func newNumError(err error, format string, args ...interface{}) error {
+	return &numError{msg: fmt.Sprintf(format, args...), err: err}
+}
@@ -0,0 +1,52 @@
+package sprigmath
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestArgError(t *testing.T) {
+	_, err := runRaw(`{{ add 1 2 "bob" }}`, nil)
+	var aerr *ArgError
+	if !errors.As(err, &aerr) {
+		t.Fatalf("Expected ArgError, got %v", err)
+	}
+	if aerr.Func != "add" || aerr.Index != 2 || aerr.Value != "bob" {
+		t.Errorf("Unexpected ArgError %#v", aerr)
+	}
+	if !errors.Is(err, ErrNotNumber) {
+		t.Errorf("Expected ErrNotNumber, got %v", err)
+	}
+
+	if err := runerr(`{{ hypot 3 "x" }}`, "hypot[arg1]: cannot convert x to float64"); err != nil {
+		t.Error(err)
+	}
+	if err := runerr(`{{ gamma "x" }}`, "gamma[arg0]: cannot convert x to float64"); err != nil {
+		t.Error(err)
+	}
+	if err := runerr(`{{ pow "x" 2 }}`, "pow[arg0]: cannot convert x to float64"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSentinels(t *testing.T) {
+	_, err := runRaw(`{{ mod 5 0 }}`, nil)
+	if !errors.Is(err, ErrDivideByZero) {
+		t.Errorf("Expected ErrDivideByZero, got %v", err)
+	}
+
+	_, err = toInt64(uint64(1 << 63))
+	if !errors.Is(err, ErrOverflow) {
+		t.Errorf("Expected ErrOverflow, got %v", err)
+	}
+
+	_, err = toNumber([]int{})
+	if !errors.Is(err, ErrNotNumber) {
+		t.Errorf("Expected ErrNotNumber, got %v", err)
+	}
+
+	if err := runt(`{{ add true 1 }}`, "2"); err != nil {
+		t.Error(err)
+	}
+}
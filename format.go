@@ -0,0 +1,187 @@
+package sprigmath
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// FloatFormat selects how float results are rendered by templates
+type FloatFormat int
+
+const (
+	// FormatShortest renders the shortest representation that round-trips,
+	// using an exponent for large and small values. This is the default,
+	// and is what text/template does with a float64.
+	FormatShortest FloatFormat = iota
+
+	// FormatPlain renders the shortest representation that round-trips,
+	// but never uses an exponent
+	FormatPlain
+
+	// FormatFixed renders exactly Options.Decimals digits after the point
+	FormatFixed
+
+	// FormatTrimmed renders at most Options.Decimals digits after the
+	// point, removing trailing zeros
+	FormatTrimmed
+)
+
+// Float is a float64 result that renders itself without an exponent. When
+// Options.FloatFormat is set, functions return a Float, or for FormatFixed
+// and FormatTrimmed a similar type that renders Options.Decimals digits.
+// These are all float64s underneath, so the template comparisons such as eq
+// and lt treat them as one, and every function accepts them as a number.
+type Float float64
+
+// Float64 implements Float64er
+func (x Float) Float64() (float64, error) {
+	return float64(x), nil
+}
+
+func (x Float) String() string {
+	return formatFloat(float64(x), FormatPlain, 0)
+}
+
+// maxDecimals is the most digits after the point that FormatFixed and
+// FormatTrimmed render. A float64 has no more than 17 significant digits.
+const maxDecimals = 17
+
+// digits is a number of decimals as the length of an array type, so that
+// each number of decimals has its own result type with a String method
+type digits interface {
+	[0]struct{} | [1]struct{} | [2]struct{} | [3]struct{} | [4]struct{} |
+		[5]struct{} | [6]struct{} | [7]struct{} | [8]struct{} | [9]struct{} |
+		[10]struct{} | [11]struct{} | [12]struct{} | [13]struct{} |
+		[14]struct{} | [15]struct{} | [16]struct{} | [17]struct{}
+}
+
+// fixedFloat is a float64 result that renders with FormatFixed
+type fixedFloat[D digits] float64
+
+func (x fixedFloat[D]) Float64() (float64, error) {
+	return float64(x), nil
+}
+
+func (x fixedFloat[D]) String() string {
+	var d D
+	return formatFloat(float64(x), FormatFixed, len(d))
+}
+
+// trimmedFloat is a float64 result that renders with FormatTrimmed
+type trimmedFloat[D digits] float64
+
+func (x trimmedFloat[D]) Float64() (float64, error) {
+	return float64(x), nil
+}
+
+func (x trimmedFloat[D]) String() string {
+	var d D
+	return formatFloat(float64(x), FormatTrimmed, len(d))
+}
+
+func decimalTypes[D digits]() [2]reflect.Type {
+	return [2]reflect.Type{reflect.TypeOf(fixedFloat[D](0)), reflect.TypeOf(trimmedFloat[D](0))}
+}
+
+// floatTypes are the FormatFixed and FormatTrimmed result types, indexed by
+// the number of decimals
+var floatTypes = [maxDecimals + 1][2]reflect.Type{
+	decimalTypes[[0]struct{}](), decimalTypes[[1]struct{}](),
+	decimalTypes[[2]struct{}](), decimalTypes[[3]struct{}](),
+	decimalTypes[[4]struct{}](), decimalTypes[[5]struct{}](),
+	decimalTypes[[6]struct{}](), decimalTypes[[7]struct{}](),
+	decimalTypes[[8]struct{}](), decimalTypes[[9]struct{}](),
+	decimalTypes[[10]struct{}](), decimalTypes[[11]struct{}](),
+	decimalTypes[[12]struct{}](), decimalTypes[[13]struct{}](),
+	decimalTypes[[14]struct{}](), decimalTypes[[15]struct{}](),
+	decimalTypes[[16]struct{}](), decimalTypes[[17]struct{}](),
+}
+
+// floatType returns the type of float results for a format. Decimals are
+// limited to 0 to maxDecimals.
+func floatType(format FloatFormat, decimals int) reflect.Type {
+	if decimals < 0 {
+		decimals = 0
+	} else if decimals > maxDecimals {
+		decimals = maxDecimals
+	}
+	switch format {
+	case FormatFixed:
+		return floatTypes[decimals][0]
+	case FormatTrimmed:
+		return floatTypes[decimals][1]
+	}
+	return reflect.TypeOf(Float(0))
+}
+
+func formatFloat(v float64, format FloatFormat, decimals int) string {
+	switch format {
+	case FormatPlain:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case FormatFixed:
+		return strconv.FormatFloat(v, 'f', decimals, 64)
+	case FormatTrimmed:
+		s := strconv.FormatFloat(v, 'f', decimals, 64)
+		if strings.IndexByte(s, '.') != -1 {
+			s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+		}
+		if s == "-0" {
+			s = "0"
+		}
+		return s
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
+
+var float64Type = reflect.TypeOf(float64(0))
+
+// formatResults wraps fn so that any float64 it returns is returned as the
+// result type for the given format instead. Functions that never return a
+// float are returned unchanged.
+func formatResults(fn interface{}, format FloatFormat, decimals int) interface{} {
+	typ := floatType(format, decimals)
+	fv := reflect.ValueOf(fn)
+	ft := fv.Type()
+
+	wrap := false
+	in := make([]reflect.Type, ft.NumIn())
+	for i := range in {
+		in[i] = ft.In(i)
+	}
+	out := make([]reflect.Type, ft.NumOut())
+	for i := range out {
+		out[i] = ft.Out(i)
+		if out[i] == float64Type {
+			out[i] = typ
+			wrap = true
+		} else if out[i].Kind() == reflect.Interface && out[i].NumMethod() == 0 {
+			wrap = true
+		}
+	}
+
+	if !wrap {
+		return fn
+	}
+
+	wt := reflect.FuncOf(in, out, ft.IsVariadic())
+	return reflect.MakeFunc(wt, func(args []reflect.Value) []reflect.Value {
+		var results []reflect.Value
+		if ft.IsVariadic() {
+			results = fv.CallSlice(args)
+		} else {
+			results = fv.Call(args)
+		}
+
+		for i, r := range results {
+			if r.Kind() == reflect.Interface && !r.IsNil() {
+				r = r.Elem()
+			}
+			if r.Kind() == reflect.Float64 && r.Type() == float64Type {
+				results[i] = r.Convert(typ).Convert(out[i])
+			}
+		}
+		return results
+	}).Interface()
+}
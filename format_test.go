@@ -0,0 +1,69 @@
+package sprigmath
+
+import (
+	"testing"
+)
+
+func TestFormatFloat(t *testing.T) {
+	for _, tc := range []struct {
+		v        float64
+		format   FloatFormat
+		decimals int
+		expected string
+	}{
+		{1e6, FormatShortest, 0, "1e+06"},
+		{1e6, FormatPlain, 0, "1000000"},
+		{0.30000000000000004, FormatPlain, 0, "0.30000000000000004"},
+		{1e-7, FormatPlain, 0, "0.0000001"},
+		{0.30000000000000004, FormatFixed, 2, "0.30"},
+		{2, FormatFixed, 3, "2.000"},
+		{0.30000000000000004, FormatTrimmed, 4, "0.3"},
+		{1e6, FormatTrimmed, 2, "1000000"},
+		{-0.001, FormatTrimmed, 2, "0"},
+		{1.25, FormatTrimmed, 1, "1.2"},
+	} {
+		if s := formatFloat(tc.v, tc.format, tc.decimals); s != tc.expected {
+			t.Errorf("formatFloat(%v, %v, %v): expected %q, got %q", tc.v, tc.format, tc.decimals, tc.expected, s)
+		}
+	}
+}
+
+func TestFloatFormatOption(t *testing.T) {
+	opts := Options{FloatFormat: FormatTrimmed, Decimals: 3}
+
+	for tpl, expected := range map[string]string{
+		`{{ div 1 3 }}`:                           "0.333",
+		`{{ mul 1000.0 1000 }}`:                   "1000000",
+		`{{ add 0.1 0.2 }}`:                       "0.3",
+		`{{ add 1 2 }}`:                           "3",
+		`{{ sqrt 2 | mul 2 }}`:                    "2.828",
+		`{{ div 1 4 | printf "%v" }}`:             "0.25",
+		`{{ float64 "2.5" | printf "%T" }}`:       "float64",
+		`{{ if eq (add 1 0.5) 1.5 }}yes{{ end }}`: "yes",
+		`{{ lt (div 1 2) 1.0 }}`:                  "true",
+		`{{ div 1 3 | add 1 }}`:                   "1.333",
+		`{{ pi }}`:                                "3.142",
+	} {
+		if out, err := runOpts(opts, tpl, nil); err != nil || out != expected {
+			t.Errorf("%s: expected %q, got %q %v", tpl, expected, out, err)
+		}
+	}
+
+	if err := testFloat(2.5, Float(2.5), ""); err != nil {
+		t.Error(err)
+	}
+
+	for tc, expected := range map[FloatFormat]string{
+		FormatPlain:   "1000000.3333333334",
+		FormatFixed:   "1000000.333",
+		FormatTrimmed: "1000000.333",
+	} {
+		out, err := runOpts(Options{FloatFormat: tc, Decimals: 3}, `{{ div 1 3 | add 1e6 }}`, nil)
+		if err != nil || out != expected {
+			t.Errorf("format %d: expected %q, got %q %v", tc, expected, out, err)
+		}
+	}
+	if out, err := runOpts(Options{FloatFormat: FormatFixed, Decimals: 40}, `{{ div 1 4 }}`, nil); err != nil || out != "0.25000000000000000" {
+		t.Errorf("expected decimals to be limited to 17, got %q %v", out, err)
+	}
+}
@@ -7,85 +7,249 @@ import (
 	"strconv"
 )
 
+// Options controls the behaviour of the functions returned by FuncMap
+type Options struct {
+	// Strict makes functions return an error wrapping ErrDomain, ErrOverflow
+	// or ErrDivideByZero instead of a NaN or infinite result when their
+	// arguments are finite. By default IEEE 754 results are returned as-is.
+	// It also makes the int and int64 conversions reject fractional and out
+	// of range values instead of truncating them.
+	Strict bool
+
+	// IntegralFloats makes functions treat a float64 with no fractional part
+	// that fits in an int64 as an int64. Values decoded from JSON or YAML
+	// are always float64, so this keeps integer results for integer data.
+	IntegralFloats bool
+
+	// SIPrefixes makes functions that accept an integer or a float also
+	// accept strings with an SI prefix or RKM code, such as "4.7k" or "4k7"
+	SIPrefixes bool
+
+	// ParseNaN makes functions accept "NaN" in strings. It is rejected by
+	// default, as it is more often a mistake than intended.
+	ParseNaN bool
+
+	// FloatFormat selects how float results are rendered. Unless it is
+	// FormatShortest, functions return a Float or a similar float64 type
+	// that renders itself. The float64 conversion still returns a float64.
+	FloatFormat FloatFormat
+
+	// Decimals is the number of digits after the point used by FormatFixed
+	// and FormatTrimmed, from 0 to 17
+	Decimals int
+
+	// Locale is a BCP 47 tag such as "de-DE". When set, every function
+	// parses strings as written in that locale. Text with a letter in it,
+	// such as "1e3" or "inf", is still read as a plain number.
+	Locale string
+
+	// Rounding is how roundCurrency and formatCurrency round amounts to the
+	// minor units of a currency. The default rounds ties away from zero.
+	Rounding RoundingMode
+
+	// Missing is what functions do with nil and missing values. By default
+	// they return an error wrapping ErrMissing.
+	Missing MissingPolicy
+}
+
+// funcs implements the template functions for a set of options
+type funcs struct {
+	Options
+
+	// funcMap is the complete map returned by FuncMap, which try calls into
+	funcMap map[string]interface{}
+}
+
+// GenericFuncMap returns sprig's generic functions, with the functions from
+// this library added using the default options
 func GenericFuncMap() map[string]interface{} {
+	return FuncMap(Options{})
+}
+
+// FuncMap returns sprig's generic functions, with the functions from this
+// library added using the given options
+func FuncMap(opts Options) map[string]interface{} {
+	return NewMath(opts).FuncMap()
+}
+
+// FuncMap returns sprig's generic functions, with the functions from this
+// library and those registered with m added
+func (m *Math) FuncMap() map[string]interface{} {
 	funcMap := sprig.GenericFuncMap()
+	opts := m.f.Options
+
+	f := &funcs{Options: opts, funcMap: funcMap}
+	functions := f.functions()
+	for k, v := range m.custom {
+		functions[k] = v
+	}
 
 	for k, v := range functions {
+		if !missingSafe[k] {
+			v = missingValues(v, opts.Missing)
+		}
+		if opts.FloatFormat != FormatShortest && k != "float64" && k != "double" {
+			v = formatResults(v, opts.FloatFormat, opts.Decimals)
+		}
 		funcMap[k] = v
 	}
 
 	return funcMap
 }
 
-var functions = map[string]interface{}{
-	// conversions
-	"atoi":    strconv.Atoi,
-	"int":     toInt,
-	"int64":   toInt64,
-	"float64": toFloat64,
-
-	// converts to an integer or float
-	"number": toNumber,
-
-	// convenience
-	"double": toFloat64,
-
-	// math in sprig that we're overriding
-	"add1":    add1,
-	"add":     add,
-	"sub":     sub,
-	"div":     div,
-	"mod":     mod,
-	"mul":     mul,
-	"biggest": max,
-	"max":     max,
-	"min":     min,
-	"ceil":    ceil,
-	"floor":   floor,
-	"round":   round,
-
-	// math
-	"acos":     acos,
-	"acosh":    acosh,
-	"asin":     asin,
-	"asinh":    asinh,
-	"atan":     atan,
-	"atan2":    atan2,
-	"atanh":    atanh,
-	"cbrt":     cbrt,
-	"copysign": copysign, // args are inverted to accomdate `computation | copysign -1`
-	"cos":      cos,
-	"cosh":     cosh,
-	"erf":      erf,
-	"erfc":     erfc,
-	"erfinv":   erfinv,
-	"exp":      exp,
-	"exp2":     exp2,
-	"expm1":    expm1,
-	"gamma":    gamma,
-	"hypot":    hypot,
-	"ilogb":    ilogb,
-	"inf":      inf,
-	"log":      log,
-	"log10":    log10,
-	"log1p":    log1p,
-	"log2":     log2,
-	"logb":     logb,
-	"pow":      pow,
-	"pow10":    pow10,
-	"signbit":  signbit,
-	"sin":      sin,
-	"sinh":     sinh,
-	"sqrt":     sqrt,
-	"tan":      tan,
-	"tanh":     tanh,
-	"trunc":    trunc,
-
-	// these are missing from the go math stdlib, but useful anyways?
-	"degrees": degrees,
-	"radians": radians,
-
-	// constants
-	"pi": func() float64 { return math.Pi },
-	"e":  func() float64 { return math.E },
+func (f *funcs) functions() map[string]interface{} {
+	return map[string]interface{}{
+		// conversions
+		"atoi":    strconv.Atoi,
+		"int":     f.int,
+		"int64":   f.int64,
+		"float64": f.float64,
+
+		// conversions that reject fractional and out of range values
+		"int8":   f.int8,
+		"int16":  f.int16,
+		"int32":  f.int32,
+		"uint8":  f.uint8,
+		"uint16": f.uint16,
+		"uint32": f.uint32,
+		"uint64": f.uint64,
+
+		// converts to an integer or float
+		"number": f.number,
+
+		// convenience
+		"double": f.float64,
+
+		// math in sprig that we're overriding
+		"add1":    f.add1,
+		"add":     f.add,
+		"sub":     f.sub,
+		"div":     f.div,
+		"mod":     f.mod,
+		"mul":     f.mul,
+		"biggest": f.max,
+		"max":     f.max,
+		"min":     f.min,
+		"ceil":    f.ceil,
+		"floor":   f.floor,
+		"round":   f.round,
+
+		// math
+		"acos":     f.acos,
+		"acosh":    f.acosh,
+		"asin":     f.asin,
+		"asinh":    f.asinh,
+		"atan":     f.atan,
+		"atan2":    f.atan2,
+		"atanh":    f.atanh,
+		"cbrt":     f.cbrt,
+		"copysign": f.copysign, // args are inverted to accomdate `computation | copysign -1`
+		"cos":      f.cos,
+		"cosh":     f.cosh,
+		"erf":      f.erf,
+		"erfc":     f.erfc,
+		"erfinv":   f.erfinv,
+		"exp":      f.exp,
+		"exp2":     f.exp2,
+		"expm1":    f.expm1,
+		"gamma":    f.gamma,
+		"hypot":    f.hypot,
+		"ilogb":    f.ilogb,
+		"inf":      f.inf,
+		"log":      f.log,
+		"log10":    f.log10,
+		"log1p":    f.log1p,
+		"log2":     f.log2,
+		"logb":     f.logb,
+		"pow":      f.pow,
+		"pow10":    f.pow10,
+		"signbit":  f.signbit,
+		"sin":      f.sin,
+		"sinh":     f.sinh,
+		"sqrt":     f.sqrt,
+		"tan":      f.tan,
+		"tanh":     f.tanh,
+		"trunc":    f.trunc,
+
+		// these are missing from the go math stdlib, but useful anyways?
+		"degrees": f.degrees,
+		"radians": f.radians,
+
+		// comparisons
+		"numEq":    f.numEq,
+		"numLt":    f.numLt,
+		"numLe":    f.numLe,
+		"numGt":    f.numGt,
+		"numGe":    f.numGe,
+		"between":  f.between,
+		"approxEq": f.approxEq,
+
+		// predicates
+		"isNumber":   f.isNumber,
+		"isInt":      f.isInt,
+		"isFloat":    f.isFloat,
+		"isFinite":   f.isFinite,
+		"isNaN":      f.isNaN,
+		"isInf":      f.isInf,
+		"isPositive": f.isPositive,
+		"isEven":     f.isEven,
+		"isOdd":      f.isOdd,
+		"inRange":    f.inRange,
+
+		// assertions
+		"assertRange":      f.assertRange,
+		"assertPositive":   f.assertPositive,
+		"assertInt":        f.assertInt,
+		"assertMultipleOf": f.assertMultipleOf,
+
+		// error handling
+		"try": f.try,
+
+		// percentages
+		"percent":       f.percent,
+		"formatPercent": f.formatPercent,
+		"percentOf":     f.percentOf,
+		"percentChange": f.percentChange,
+		"ratio":         f.ratio,
+
+		// SI prefixed quantities
+		"parseSI": f.parseSI,
+
+		// units
+		"convert":  f.convert,
+		"quantity": f.quantity,
+		"toUnit":   f.toUnit,
+
+		// scientific and engineering notation
+		"sci":        f.sci,
+		"sciUnicode": f.sciUnicode,
+		"eng":        f.eng,
+		"engExp":     f.engExp,
+		"engUnicode": f.engUnicode,
+
+		// fractions
+		"toFraction":     f.toFraction,
+		"formatFraction": f.formatFraction,
+		"formatMixed":    f.formatMixed,
+
+		// numbers as words
+		"toWords":   f.toWords,
+		"ordinal":   f.ordinal,
+		"toRoman":   f.toRoman,
+		"fromRoman": f.fromRoman,
+
+		// locale aware formatting
+		"formatNumber": f.formatNumber,
+		"parseNumber":  f.parseNumber,
+
+		// currencies
+		"formatCurrency": f.formatCurrency,
+		"roundCurrency":  f.roundCurrency,
+		"allocate":       f.allocate,
+		"allocateRatios": f.allocateRatios,
+
+		// constants
+		"pi": func() float64 { return math.Pi },
+		"e":  func() float64 { return math.E },
+	}
 }
@@ -67,3 +67,14 @@ func runRaw(tpl string, vars interface{}) (string, error) {
 	}
 	return b.String(), nil
 }
+
+// runOpts runs a template using a func map created with the given options
+func runOpts(opts Options, tpl string, vars interface{}) (string, error) {
+	t := template.Must(template.New("test").Funcs(FuncMap(opts)).Parse(tpl))
+	var b bytes.Buffer
+	err := t.Execute(&b, vars)
+	if err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,391 @@
+package sprigmath
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// numberSymbols describes how numbers are written in a locale
+type numberSymbols struct {
+	decimal string
+	group   string
+	minus   string
+
+	// secondary is the size of the groups after the first one, which is
+	// 2 in India and 3 everywhere else
+	secondary int
+
+	// minGrouping is the number of digits that must be in front of the
+	// first group separator before grouping is used at all
+	minGrouping int
+}
+
+var (
+	symbolsEnglish = numberSymbols{".", ",", "-", 3, 1}
+	symbolsIndian  = numberSymbols{".", ",", "-", 2, 1}
+	symbolsGerman  = numberSymbols{",", ".", "-", 3, 1}
+	symbolsSwiss   = numberSymbols{".", "\u2019", "-", 3, 1}
+	symbolsFrench  = numberSymbols{",", "\u202f", "-", 3, 1}
+	symbolsSpace   = numberSymbols{",", "\u00a0", "-", 3, 1}
+	symbolsNordic  = numberSymbols{",", "\u00a0", "\u2212", 3, 1}
+)
+
+// locales maps lower case BCP 47 tags to the way numbers are written there.
+// Tags that are not listed fall back to their language.
+var locales = map[string]numberSymbols{
+	"en":    symbolsEnglish,
+	"en-us": symbolsEnglish,
+	"en-gb": symbolsEnglish,
+	"en-au": symbolsEnglish,
+	"en-ca": symbolsEnglish,
+	"en-ie": symbolsEnglish,
+	"en-nz": symbolsEnglish,
+	"en-sg": symbolsEnglish,
+	"en-in": symbolsIndian,
+	"en-za": symbolsSpace,
+	"hi":    symbolsIndian,
+	"hi-in": symbolsIndian,
+	"ja":    symbolsEnglish,
+	"ja-jp": symbolsEnglish,
+	"ko":    symbolsEnglish,
+	"ko-kr": symbolsEnglish,
+	"zh":    symbolsEnglish,
+	"zh-cn": symbolsEnglish,
+	"zh-tw": symbolsEnglish,
+	"th":    symbolsEnglish,
+	"he":    symbolsEnglish,
+	"de":    symbolsGerman,
+	"de-de": symbolsGerman,
+	"de-at": symbolsSpace,
+	"de-ch": symbolsSwiss,
+	"it":    symbolsGerman,
+	"it-it": symbolsGerman,
+	"it-ch": symbolsSwiss,
+	"fr":    symbolsFrench,
+	"fr-fr": symbolsFrench,
+	"fr-ch": symbolsFrench,
+	"fr-be": symbolsFrench,
+	"fr-ca": symbolsSpace,
+	"es":    {",", ".", "-", 3, 2},
+	"es-es": {",", ".", "-", 3, 2},
+	"es-mx": symbolsEnglish,
+	"es-us": symbolsEnglish,
+	"pt":    symbolsGerman,
+	"pt-br": symbolsGerman,
+	"pt-pt": {",", "\u00a0", "-", 3, 2},
+	"nl":    symbolsGerman,
+	"nl-nl": symbolsGerman,
+	"nl-be": symbolsGerman,
+	"da":    symbolsGerman,
+	"da-dk": symbolsGerman,
+	"tr":    symbolsGerman,
+	"tr-tr": symbolsGerman,
+	"id":    symbolsGerman,
+	"id-id": symbolsGerman,
+	"vi":    symbolsGerman,
+	"vi-vn": symbolsGerman,
+	"sv":    symbolsNordic,
+	"sv-se": symbolsNordic,
+	"nb":    symbolsNordic,
+	"nb-no": symbolsNordic,
+	"no":    symbolsNordic,
+	"fi":    symbolsNordic,
+	"fi-fi": symbolsNordic,
+	"pl":    {",", "\u00a0", "-", 3, 2},
+	"pl-pl": {",", "\u00a0", "-", 3, 2},
+	"cs":    symbolsSpace,
+	"cs-cz": symbolsSpace,
+	"ru":    symbolsSpace,
+	"ru-ru": symbolsSpace,
+	"uk":    symbolsSpace,
+	"uk-ua": symbolsSpace,
+}
+
+// lookupLocale finds the number symbols for a BCP 47 tag such as "de-DE"
+func lookupLocale(tag string) (numberSymbols, error) {
+	key := strings.ToLower(strings.Replace(strings.TrimSpace(tag), "_", "-", -1))
+	if sym, ok := locales[key]; ok {
+		return sym, nil
+	}
+	if i := strings.IndexByte(key, '-'); i != -1 {
+		if sym, ok := locales[key[:i]]; ok {
+			return sym, nil
+		}
+	}
+	return numberSymbols{}, newNumError(ErrDomain, "unknown locale %q", tag)
+}
+
+// decimalString renders v as plain decimal digits, without an exponent, with
+// the given number of digits after the point, or as many as are needed when
+// decimals is negative. Values are converted exactly with toRat.
+func decimalString(v interface{}, decimals int) (string, error) {
+	switch x := v.(type) {
+	case *big.Float:
+		if x != nil {
+			return x.Text('f', decimals), nil
+		}
+	case Decimal:
+		if decimals < 0 {
+			decimals = x.scale
+		}
+	}
+
+	n, err := toNumber(v)
+	if err != nil {
+		return "", err
+	}
+	if fv, ok := n.(float64); ok && (math.IsNaN(fv) || math.IsInf(fv, 0)) {
+		return strconv.FormatFloat(fv, 'f', decimals, 64), nil
+	}
+
+	r, err := toRat(v)
+	if err != nil {
+		return "", err
+	}
+
+	if decimals < 0 {
+		prec, exact := r.FloatPrec()
+		if !exact {
+			fv, _ := r.Float64()
+			return strconv.FormatFloat(fv, 'f', -1, 64), nil
+		}
+		decimals = prec
+	}
+	return r.FloatString(decimals), nil
+}
+
+// localize rewrites plain decimal digits as written in a locale, grouping
+// the integer part if requested and padding it to at least minDigits digits
+func localize(s string, sym numberSymbols, grouping bool, minDigits int) string {
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+	// NaN and infinities are not digits
+	if s == "" || s[0] < '0' || s[0] > '9' {
+		return s
+	}
+
+	intPart, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i != -1 {
+		intPart, frac = s[:i], s[i+1:]
+	}
+
+	if len(intPart) < minDigits {
+		intPart = strings.Repeat("0", minDigits-len(intPart)) + intPart
+	}
+
+	if grouping && len(intPart) >= 3+sym.minGrouping {
+		head, groups := intPart[:len(intPart)-3], []string{intPart[len(intPart)-3:]}
+		for len(head) > sym.secondary {
+			groups = append([]string{head[len(head)-sym.secondary:]}, groups...)
+			head = head[:len(head)-sym.secondary]
+		}
+		intPart = head + sym.group + strings.Join(groups, sym.group)
+	}
+
+	if frac != "" {
+		intPart += sym.decimal + frac
+	}
+	if neg {
+		intPart = sym.minus + intPart
+	}
+	return intPart
+}
+
+// numberOptions are the options accepted by formatNumber, given as a dict
+type numberOptions struct {
+	decimals  int
+	grouping  bool
+	minDigits int
+}
+
+func parseNumberOptions(name string, i int, opts map[string]interface{}) (numberOptions, error) {
+	o := numberOptions{decimals: -1, grouping: true}
+	for k, v := range opts {
+		var err error
+		switch k {
+		case "decimals":
+			o.decimals, err = toInt(v)
+		case "minDigits":
+			o.minDigits, err = toInt(v)
+		case "grouping":
+			var iv int64
+			iv, err = toInt64(v)
+			o.grouping = iv != 0
+		default:
+			err = newNumError(ErrDomain, "unknown option %q", k)
+		}
+		if err != nil {
+			return o, argError(name, i, opts, err)
+		}
+	}
+	return o, nil
+}
+
+// formatNumber is called as `formatNumber locale [options] value`, so that
+// the value can be piped in. options is a dict with the keys "decimals",
+// "grouping" and "minDigits".
+func (f *funcs) formatNumber(locale string, args ...interface{}) (string, error) {
+	if len(args) == 0 || len(args) > 2 {
+		return "", argError("formatNumber", -1, args, newNumError(ErrDomain, "expected [options] value"))
+	}
+
+	sym, err := lookupLocale(locale)
+	if err != nil {
+		return "", argError("formatNumber", 0, locale, err)
+	}
+
+	o := numberOptions{decimals: -1, grouping: true}
+	if len(args) == 2 {
+		opts, ok := args[0].(map[string]interface{})
+		if !ok {
+			return "", argError("formatNumber", 1, args[0], newNumError(ErrDomain, "options must be a dict"))
+		}
+		if o, err = parseNumberOptions("formatNumber", 1, opts); err != nil {
+			return "", err
+		}
+	}
+
+	v := args[len(args)-1]
+	lit, err := f.literal(v)
+	if err != nil {
+		return "", argError("formatNumber", len(args), v, err)
+	}
+	s, err := decimalString(lit, o.decimals)
+	if err != nil {
+		return "", argError("formatNumber", len(args), v, err)
+	}
+
+	return localize(s, sym, o.grouping, o.minDigits), nil
+}
+
+// isGroupSeparator reports whether r separates groups of digits in a locale
+// whose separator is group. Any kind of space is accepted for a space, and an
+// apostrophe for a right single quotation mark.
+func isGroupSeparator(r rune, group string) bool {
+	switch group {
+	case "\u00a0", "\u202f":
+		return r == ' ' || r == '\u00a0' || r == '\u202f'
+	case "\u2019":
+		return r == '\u2019' || r == '\''
+	}
+	return string(r) == group
+}
+
+// delocalize rewrites a number written in a locale as plain decimal digits
+// that strconv can parse. Group separators must be in the right places, so
+// that text written for another locale is rejected rather than misread.
+func delocalize(s string, sym numberSymbols) (string, bool) {
+	s = strings.TrimSpace(s)
+
+	var b strings.Builder
+	switch {
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	case strings.HasPrefix(s, "-"):
+		s = s[1:]
+		b.WriteByte('-')
+	case strings.HasPrefix(s, sym.minus):
+		s = s[len(sym.minus):]
+		b.WriteByte('-')
+	}
+
+	intPart, frac := s, ""
+	if i := strings.Index(s, sym.decimal); i != -1 {
+		intPart, frac = s[:i], s[i+len(sym.decimal):]
+		if frac == "" {
+			return "", false
+		}
+	}
+
+	groups := []string{""}
+	for _, r := range intPart {
+		if isGroupSeparator(r, sym.group) {
+			groups = append(groups, "")
+		} else if r >= '0' && r <= '9' {
+			groups[len(groups)-1] += string(r)
+		} else {
+			return "", false
+		}
+	}
+
+	// the first group may be short, the last has three digits and those in
+	// between are secondary groups
+	if len(groups) > 1 {
+		first, last := groups[0], groups[len(groups)-1]
+		if len(first) == 0 || len(first) > 3 || (len(groups) > 2 && len(first) > sym.secondary) || len(last) != 3 {
+			return "", false
+		}
+		for _, g := range groups[1 : len(groups)-1] {
+			if len(g) != sym.secondary {
+				return "", false
+			}
+		}
+	} else if groups[0] == "" {
+		return "", false
+	}
+
+	for _, r := range frac {
+		if r < '0' || r > '9' {
+			return "", false
+		}
+	}
+
+	b.WriteString(strings.Join(groups, ""))
+	if frac != "" {
+		b.WriteByte('.')
+		b.WriteString(frac)
+	}
+	return b.String(), true
+}
+
+// delocalizeIn rewrites a number written in the given locale as plain digits
+func delocalizeIn(locale string, s string) (string, error) {
+	sym, err := lookupLocale(locale)
+	if err != nil {
+		return "", err
+	}
+
+	plain, ok := delocalize(s, sym)
+	if !ok {
+		return "", newNumError(ErrNotNumber, "cannot parse %q as a number in %s", s, locale)
+	}
+	return plain, nil
+}
+
+// parseLocale parses a number written in the given locale
+func parseLocale(locale string, s string) (interface{}, error) {
+	plain, err := delocalizeIn(locale, s)
+	if err != nil {
+		return nil, err
+	}
+	return toNumber(plain)
+}
+
+// parseNumber parses a string written in a locale to an int64 or a float64
+func (f *funcs) parseNumber(locale string, s string) (interface{}, error) {
+	n, err := parseLocale(locale, s)
+	if err != nil {
+		return nil, argError("parseNumber", 1, s, err)
+	}
+	return f.toNumber(n)
+}
+
+// number is the "number" conversion
+func (f *funcs) number(v interface{}) (interface{}, error) {
+	n, err := f.toNumber(v)
+	if err != nil {
+		return nil, argError("number", 0, v, err)
+	}
+	return n, nil
+}
+
+// float64 is the "float64" conversion
+func (f *funcs) float64(v interface{}) (float64, error) {
+	fv, err := f.toFloat64(v)
+	if err != nil {
+		return 0, argError("float64", 0, v, err)
+	}
+	return fv, nil
+}
@@ -0,0 +1,139 @@
+package sprigmath
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+)
+
+func TestFormatNumber(t *testing.T) {
+	huge, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	vars := map[string]interface{}{
+		"huge":   huge,
+		"amount": json.Number("1234567.885"),
+		"third":  big.NewRat(1, 3),
+	}
+
+	for tpl, expected := range map[string]string{
+		`{{ formatNumber "en-US" 1234567.89 }}`:                         "1,234,567.89",
+		`{{ formatNumber "de-DE" 1234567.89 }}`:                         "1.234.567,89",
+		`{{ formatNumber "en-IN" 1234567 }}`:                            "12,34,567",
+		`{{ formatNumber "fr-FR" -1234.5 }}`:                            "-1\u202f234,5",
+		`{{ formatNumber "sv_SE" -1234 }}`:                              "\u22121\u00a0234",
+		`{{ formatNumber "es" 1234 }} {{ formatNumber "es" 12345 }}`:    "1234 12.345",
+		`{{ formatNumber "de-LU" 1234 }}`:                               "1.234",
+		`{{ 1234.5 | formatNumber "en" (dict "decimals" 2) }}`:          "1,234.50",
+		`{{ 1234.5 | formatNumber "en" (dict "grouping" false) }}`:      "1234.5",
+		`{{ 7 | formatNumber "en" (dict "minDigits" 3 "decimals" 1) }}`: "007.0",
+		`{{ formatNumber "en" .huge }}`:                                 "123,456,789,012,345,678,901,234,567,890",
+		`{{ formatNumber "de" (dict "decimals" 2) .amount }}`:           "1.234.567,89",
+		`{{ formatNumber "en" (dict "decimals" 4) .third }}`:            "0.3333",
+		`{{ formatNumber "en" "1e21" }}`:                                "1,000,000,000,000,000,000,000",
+	} {
+		if out, err := runRaw(tpl, vars); err != nil || out != expected {
+			t.Errorf("%s: expected %q, got %q %v", tpl, expected, out, err)
+		}
+	}
+
+	if err := runerr(`{{ formatNumber "xx" 1 }}`, `formatNumber[arg0]: unknown locale "xx"`); err != nil {
+		t.Error(err)
+	}
+	if err := runerr(`{{ formatNumber "en" "bob" }}`, `formatNumber[arg1]: bob is not a float64 or int64`); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestParseNumber(t *testing.T) {
+	for _, tc := range []struct {
+		locale, s string
+		expected  interface{}
+	}{
+		{"de-DE", "1.234,5", 1234.5},
+		{"fr-FR", "1 234,5", 1234.5},
+		{"fr-FR", "1\u202f234,5", 1234.5},
+		{"en-US", " +1,234,567 ", int64(1234567)},
+		{"en-US", "1.5", 1.5},
+		{"en-IN", "12,34,567.25", 1234567.25},
+		{"de-CH", "1'234.50", 1234.5},
+		{"sv-SE", "\u22121 234", int64(-1234)},
+		{"de-DE", "-0,5", -0.5},
+		{"de-DE", "1234", int64(1234)},
+	} {
+		if n, err := parseLocale(tc.locale, tc.s); err != nil || n != tc.expected {
+			t.Errorf("parseLocale(%q, %q): expected %v, got %v %v", tc.locale, tc.s, tc.expected, n, err)
+		}
+	}
+
+	for _, tc := range []struct{ locale, s string }{
+		{"de-DE", "1,234.5"},
+		{"en-US", "1,23,4"},
+		{"en-US", "1,,234"},
+		{"en-US", "1.234.5"},
+		{"en-US", ",234"},
+		{"en-US", "1,234,"},
+		{"de-DE", "1,"},
+		{"en-US", "12 34"},
+		{"en-US", ""},
+	} {
+		if n, err := parseLocale(tc.locale, tc.s); err == nil {
+			t.Errorf("parseLocale(%q, %q): expected an error, got %v", tc.locale, tc.s, n)
+		}
+	}
+
+	if err := runt(`{{ parseNumber "de-DE" "1.234,5" | add 1 }}`, "1235.5"); err != nil {
+		t.Error(err)
+	}
+	if err := runerr(`{{ parseNumber "de-DE" "1,234.5" }}`, `parseNumber[arg1]: cannot parse "1,234.5" as a number in de-DE`); err != nil {
+		t.Error(err)
+	}
+
+	opts := Options{Locale: "de-DE"}
+	if out, err := runOpts(opts, `{{ number "1.234" }} {{ float64 "0,5" }} {{ add "1.234" 1 }} {{ max "2,5" 1 }}`, nil); err != nil || out != "1234 0.5 1235 2.5" {
+		t.Errorf("Expected '1234 0.5 1235 2.5', got %q %v", out, err)
+	}
+	for tpl, errstr := range map[string]string{
+		`{{ number "1,234.5" }}`:  `number[arg0]: cannot parse "1,234.5" as a number in de-DE`,
+		`{{ float64 "1,234.5" }}`: `float64[arg0]: cannot parse "1,234.5" as a number in de-DE`,
+		`{{ add 1 "1,234.5" }}`:   `add[arg1]: cannot parse "1,234.5" as a number in de-DE`,
+	} {
+		_, err := runOpts(opts, tpl, nil)
+		if err = testError(errstr, err); err != nil {
+			t.Errorf("%s: %v", tpl, err)
+		}
+	}
+	for _, locale := range []string{"en-US", "de-DE"} {
+		opts := Options{Locale: locale}
+		if out, err := runOpts(opts, `{{ number "1e3" }} {{ number "inf" }} {{ number "0x10" }} {{ float64 "-2.5E-1" }} {{ int "1e2" }}`, nil); err != nil || out != "1000 +Inf 16 -0.25 100" {
+			t.Errorf("%s: expected '1000 +Inf 16 -0.25 100', got %q %v", locale, out, err)
+		}
+	}
+	if out, err := runOpts(Options{Locale: "de-DE", ParseNaN: true}, `{{ number "NaN" }} {{ number "1.234" }}`, nil); err != nil || out != "NaN 1234" {
+		t.Errorf("Expected 'NaN 1234', got %q %v", out, err)
+	}
+}
+
+func TestLocaleEveryFunction(t *testing.T) {
+	opts := Options{Locale: "de-DE"}
+	for tpl, expected := range map[string]string{
+		`{{ percent "12,5%" }}`:                   "0.125",
+		`{{ formatPercent 1 "0,1234" }}`:          "12.3%",
+		`{{ roundCurrency "EUR" "1.234,567" }}`:   "1234.57",
+		`{{ allocate "1.234,50" 2 }}`:             "[617.25 617.25]",
+		`{{ sci 3 "1.234,5" }}`:                   "1.23e3",
+		`{{ toWords "en" "1.234" }}`:              "one thousand two hundred thirty-four",
+		`{{ formatFraction "0,75" }}`:             "3/4",
+		`{{ assertMultipleOf "x" "0,1" "0,3" }}`:  "0,3",
+		`{{ approxEq "0,5" 1 "1,4" }}`:            "true",
+		`{{ parseSI "1.234,5" }}`:                 "1234.5",
+		`{{ int "1.234" }} {{ int64 "-2.000" }}`:  "1234 -2000",
+		`{{ pow10 "2" }}`:                         "100",
+		`{{ formatNumber "en-US" "1.234,5" }}`:    "1,234.5",
+		`{{ add (roundCurrency "EUR" 1) "0,5" }}`: "1.50",
+		`{{ add (toFraction 10 "0,5") "0,25" }}`:  "3/4",
+		`{{ convert "1.000" "m" "km" }}`:          "1",
+	} {
+		if out, err := runOpts(opts, tpl, nil); err != nil || out != expected {
+			t.Errorf("%s: expected %q, got %q %v", tpl, expected, out, err)
+		}
+	}
+}
@@ -1,615 +1,816 @@
 package sprigmath
 
 import (
-	"fmt"
 	"math"
-
-	"github.com/pkg/errors"
+	"math/big"
 )
 
-func initialArg(name string, a interface{}, iv int64, fv float64) (int64, float64, bool, error) {
-	hasFloat := false
-	an, err := toNumber(a)
+// literal reads a string argument the way the options say: as written in
+// Locale, with an SI prefix, or as NaN. The result is a plain string, a
+// Decimal or a float64, so that the conversions can still read it exactly.
+// Text with letters in it, such as "1e3", "inf" or "0x10", is never written
+// in a locale, so it is left for the literal grammar. Other values are
+// returned as they are.
+func (f *funcs) literal(v interface{}) (interface{}, error) {
+	str, ok := v.(string)
+	if !ok {
+		return v, nil
+	}
+
+	if f.ParseNaN {
+		if n, ok := parseLiteral(str, true); ok {
+			if fv, ok := n.(float64); ok && math.IsNaN(fv) {
+				return fv, nil
+			}
+		}
+	}
+	if f.Locale != "" && !hasLetter(str) {
+		return delocalizeIn(f.Locale, str)
+	}
+	if f.SIPrefixes {
+		if _, ok := parseLiteral(str, false); !ok {
+			if r, unit, ok := parseSIText(str, ""); ok && unit == "" {
+				return siDecimal(r), nil
+			}
+		}
+	}
+	return str, nil
+}
+
+// toNumber converts to either an int64 or a float64, honouring IntegralFloats,
+// SIPrefixes, ParseNaN and Locale
+func (f *funcs) toNumber(v interface{}) (interface{}, error) {
+	v, err := f.literal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := toNumber(v)
+	if err != nil || !f.IntegralFloats {
+		return n, err
+	}
+	return integralFloat(n), nil
+}
+
+// toFloat64 converts to a float64, parsing strings like toNumber
+func (f *funcs) toFloat64(v interface{}) (float64, error) {
+	v, err := f.literal(v)
+	if err != nil {
+		return 0, err
+	}
+	return toFloat64(v)
+}
+
+// numberArg converts the i-th argument of the named function to a number.
+// The value is always returned as a float64, and also as an int64 unless the
+// argument was a float.
+func (f *funcs) numberArg(name string, i int, v interface{}) (int64, float64, bool, error) {
+	n, err := f.toNumber(v)
 	if err != nil {
-		return 0, 0, false, errors.WithMessage(err, name+"[arg0]")
+		return 0, 0, false, argError(name, i, v, err)
 	}
 
-	switch av := an.(type) {
-	case int64:
-		iv = av
-	case float64:
-		hasFloat = true
-		fv = av
-	default:
-		return 0, 0, false, errors.New("inconceivable")
+	if fv, ok := n.(float64); ok {
+		return 0, fv, true, nil
 	}
 
-	return iv, fv, hasFloat, err
+	iv := n.(int64)
+	return iv, float64(iv), false, nil
+}
+
+// checkFloat implements strict mode for functions that return a float. A NaN
+// result computed from finite arguments is a domain error, and an infinite
+// result is reported as inf, which is ErrOverflow unless the function has a
+// pole there. Non-finite arguments are passed through, as is everything when
+// not in strict mode.
+func (f *funcs) checkFloat(name string, r float64, inf error, args ...float64) (float64, error) {
+	if !f.Strict || !(math.IsNaN(r) || math.IsInf(r, 0)) {
+		return r, nil
+	}
+
+	for _, a := range args {
+		if math.IsNaN(a) || math.IsInf(a, 0) {
+			return r, nil
+		}
+	}
+
+	var v interface{} = args
+	i := -1
+	if len(args) == 1 {
+		v = args[0]
+		i = 0
+	}
+
+	if math.IsNaN(r) || inf == ErrDomain {
+		return 0, argError(name, i, v, newNumError(ErrDomain, "%v is outside of the domain", v))
+	}
+	return 0, argError(name, i, v, newNumError(inf, "result overflows for %v", v))
+}
+
+// checkInt implements strict mode for functions that round a float to an
+// int64, rejecting results that cannot be represented.
+func (f *funcs) checkInt(name string, r float64, arg float64) (int64, error) {
+	if f.Strict && !(r >= math.MinInt64 && r < math.MaxInt64) {
+		return 0, argError(name, 0, arg, newNumError(ErrOverflow, "%v overflows int64", r))
+	}
+	return int64(r), nil
+}
+
+// addInt64 adds two int64, and reports false if the result overflows
+func addInt64(a, b int64) (int64, bool) {
+	c := a + b
+	return c, (c > a) == (b > 0)
+}
+
+// mulInt64 multiplies two int64, and reports false if the result overflows
+func mulInt64(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	c := a * b
+	if c/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return c, false
+	}
+	return c, true
+}
+
+// intOverflow calculates the sum or product of args when an int64 overflowed
+// along the way. With a float argument the result is a float, as usual.
+// Otherwise it is calculated exactly, and is an int64 if it fits, and if not
+// an error in strict mode or a float64.
+func (f *funcs) intOverflow(name string, args []interface{}, mul bool, hasFloat bool, in []float64) (interface{}, error) {
+	if hasFloat {
+		r := in[0]
+		for _, fv := range in[1:] {
+			if mul {
+				r *= fv
+			} else {
+				r += fv
+			}
+		}
+		return f.checkFloat(name, r, ErrOverflow, in...)
+	}
+
+	var r *big.Int
+	for i, arg := range args {
+		iv, _, _, _ := f.numberArg(name, i, arg)
+		x := big.NewInt(iv)
+		switch {
+		case r == nil:
+			r = x
+		case mul:
+			r.Mul(r, x)
+		default:
+			r.Add(r, x)
+		}
+	}
+
+	return f.bigIntResult(name, args, r)
+}
+
+// bigIntResult returns an exact integer result as an int64 if it fits, and
+// otherwise fails in strict mode or returns it as a float64
+func (f *funcs) bigIntResult(name string, args []interface{}, r *big.Int) (interface{}, error) {
+	if r.IsInt64() {
+		return r.Int64(), nil
+	}
+	if f.Strict {
+		return nil, argError(name, -1, args, newNumError(ErrOverflow, "result overflows int64 for %v", args))
+	}
+	fv, _ := new(big.Float).SetInt(r).Float64()
+	return fv, nil
 }
 
 //
 // math currently present in sprig
 //
 
-func add1(a interface{}) (interface{}, error) {
-	a, err := toNumber(a)
+func (f *funcs) add1(a interface{}) (interface{}, error) {
+	iv, fv, isFloat, err := f.numberArg("add1", 0, a)
 	if err != nil {
-		return nil, errors.WithMessage(err, "add1")
+		return nil, err
 	}
 
-	switch av := a.(type) {
-	case int64:
-		return av + 1, nil
-	case float64:
-		return av + 1, nil
+	if isFloat {
+		return fv + 1, nil
 	}
 
-	return nil, errors.New("inconceivable")
+	return iv + 1, nil
 }
 
-func add(a interface{}, args ...interface{}) (interface{}, error) {
-	ival, fval, hasFloat, err := initialArg("add", a, 0, 0.0)
-	if err != nil {
-		return nil, err
+func (f *funcs) add(a interface{}, args ...interface{}) (interface{}, error) {
+	all := append([]interface{}{a}, args...)
+	if hasQuantity(all) {
+		return f.quantitySum("add", all, 1)
+	}
+	if hasRational(all) {
+		return f.ratFold("add", all, (*big.Rat).Add)
+	}
+	if hasDecimal(all) {
+		return f.decimalFold("add", all, (*big.Rat).Add, false)
 	}
 
-	for i, arg := range args {
-		an, err := toNumber(arg)
+	var ival int64
+	var fval float64
+	hasFloat, overflow := false, false
+	var in []float64
+
+	for i, arg := range all {
+		iv, fv, isFloat, err := f.numberArg("add", i, arg)
 		if err != nil {
-			return nil, errors.WithMessage(err, fmt.Sprintf("add[arg%d]:", i+1))
+			return nil, err
 		}
-		switch av := an.(type) {
-		case int64:
-			ival += av
-		case float64:
+		in = append(in, fv)
+		if isFloat {
 			hasFloat = true
-			fval += av
-		default:
-			return nil, errors.New("inconceivable")
+			fval += fv
+		} else if sum, ok := addInt64(ival, iv); ok && !overflow {
+			ival = sum
+		} else {
+			overflow = true
 		}
 	}
 
+	if overflow {
+		return f.intOverflow("add", all, false, hasFloat, in)
+	}
 	if hasFloat {
-		return float64(ival) + fval, nil
+		return f.checkFloat("add", float64(ival)+fval, ErrOverflow, in...)
 	}
 
 	return ival, nil
 }
 
-func sub(a interface{}, b interface{}) (interface{}, error) {
-	a, err := toNumber(a)
+func (f *funcs) sub(a interface{}, b interface{}) (interface{}, error) {
+	if hasQuantity([]interface{}{a, b}) {
+		return f.quantitySum("sub", []interface{}{a, b}, -1)
+	}
+	if hasRational([]interface{}{a, b}) {
+		return f.ratFold("sub", []interface{}{a, b}, (*big.Rat).Sub)
+	}
+	if hasDecimal([]interface{}{a, b}) {
+		return f.decimalFold("sub", []interface{}{a, b}, (*big.Rat).Sub, false)
+	}
+
+	ai, af, aFloat, err := f.numberArg("sub", 0, a)
 	if err != nil {
-		return nil, errors.WithMessage(err, "sub[a]")
+		return nil, err
 	}
 
-	b, err = toNumber(b)
+	bi, bf, bFloat, err := f.numberArg("sub", 1, b)
 	if err != nil {
-		return nil, errors.WithMessage(err, "sub[b]")
+		return nil, err
 	}
 
-	switch av := a.(type) {
-	case int64:
-		switch bv := b.(type) {
-		case int64:
-			return av - bv, nil
-		case float64:
-			return float64(av) - bv, nil
-		}
+	if aFloat || bFloat {
+		return f.checkFloat("sub", af-bf, ErrOverflow, af, bf)
+	}
 
-	case float64:
-		switch bv := b.(type) {
-		case int64:
-			return av - float64(bv), nil
-		case float64:
-			return av - bv, nil
+	if r, ok := addInt64(ai, -bi); ok && bi != math.MinInt64 {
+		return r, nil
+	}
+	return f.bigIntResult("sub", []interface{}{a, b}, new(big.Int).Sub(big.NewInt(ai), big.NewInt(bi)))
+}
+
+func (f *funcs) div(a interface{}, b interface{}) (interface{}, error) {
+	if hasQuantity([]interface{}{a, b}) {
+		return f.quantityProduct("div", []interface{}{a, b}, true)
+	}
+	if hasRational([]interface{}{a, b}) {
+		if r, err := f.toRat(b); err == nil && r.Sign() == 0 {
+			return nil, argError("div", 1, b, ErrDivideByZero)
 		}
+		return f.ratFold("div", []interface{}{a, b}, (*big.Rat).Quo)
 	}
 
-	return nil, errors.New("inconceivable")
+	return f.quo("div", a, b)
 }
 
-func div(a interface{}, b interface{}) (float64, error) {
-	a, err := toNumber(a)
+// quo divides a by b for the named function. Division by zero follows the
+// same policy everywhere: it is an error in strict mode, and IEEE 754
+// otherwise.
+func (f *funcs) quo(name string, a interface{}, b interface{}) (float64, error) {
+	_, af, _, err := f.numberArg(name, 0, a)
 	if err != nil {
-		return 0, errors.WithMessage(err, "div[a]")
+		return 0, err
 	}
 
-	b, err = toNumber(b)
+	_, bf, _, err := f.numberArg(name, 1, b)
 	if err != nil {
-		return 0, errors.WithMessage(err, "div[b]")
+		return 0, err
 	}
 
-	switch av := a.(type) {
-	case int64:
-		switch bv := b.(type) {
-		case int64:
-			return float64(av) / float64(bv), nil
-		case float64:
-			return float64(av) / bv, nil
-		}
-
-	case float64:
-		switch bv := b.(type) {
-		case int64:
-			return av / float64(bv), nil
-		case float64:
-			return av / bv, nil
-		}
+	if f.Strict && bf == 0 && !math.IsNaN(af) && !math.IsInf(af, 0) {
+		return 0, argError(name, 1, b, ErrDivideByZero)
 	}
 
-	return 0, errors.New("inconceivable")
+	return f.checkFloat(name, af/bf, ErrOverflow, af, bf)
 }
 
-func mod(a interface{}, b interface{}) (interface{}, error) {
-	a, err := toNumber(a)
+func (f *funcs) mod(a interface{}, b interface{}) (interface{}, error) {
+	if hasQuantity([]interface{}{a, b}) {
+		return f.quantityMod(a, b)
+	}
+
+	ai, af, aFloat, err := f.numberArg("mod", 0, a)
 	if err != nil {
-		return nil, errors.WithMessage(err, "mod[a]")
+		return nil, err
 	}
 
-	b, err = toNumber(b)
+	bi, bf, bFloat, err := f.numberArg("mod", 1, b)
 	if err != nil {
-		return nil, errors.WithMessage(err, "mod[b]")
+		return nil, err
 	}
 
-	switch av := a.(type) {
-	case int64:
-		switch bv := b.(type) {
-		case int64:
-			return av % bv, nil
-		case float64:
-			return math.Mod(float64(av), bv), nil
+	if aFloat || bFloat {
+		if f.Strict && bf == 0 && !math.IsNaN(af) && !math.IsInf(af, 0) {
+			return nil, argError("mod", 1, b, ErrDivideByZero)
 		}
+		return f.checkFloat("mod", math.Mod(af, bf), ErrOverflow, af, bf)
+	}
 
-	case float64:
-		switch bv := b.(type) {
-		case int64:
-			return math.Mod(av, float64(bv)), nil
-		case float64:
-			return math.Mod(av, bv), nil
-		}
+	if bi == 0 {
+		return nil, argError("mod", 1, b, ErrDivideByZero)
 	}
 
-	return nil, errors.New("inconceivable")
+	return ai % bi, nil
 }
 
-func mul(a interface{}, args ...interface{}) (interface{}, error) {
-	ival, fval, hasFloat, err := initialArg("mul", a, 1, 1.0)
-	if err != nil {
-		return nil, err
+func (f *funcs) mul(a interface{}, args ...interface{}) (interface{}, error) {
+	all := append([]interface{}{a}, args...)
+	if hasQuantity(all) {
+		return f.quantityProduct("mul", all, false)
+	}
+	if hasRational(all) {
+		return f.ratFold("mul", all, (*big.Rat).Mul)
+	}
+	if hasDecimal(all) {
+		return f.decimalFold("mul", all, (*big.Rat).Mul, true)
 	}
 
-	for i, arg := range args {
-		an, err := toNumber(arg)
+	ival := int64(1)
+	fval := 1.0
+	hasFloat, overflow := false, false
+	var in []float64
+
+	for i, arg := range all {
+		iv, fv, isFloat, err := f.numberArg("mul", i, arg)
 		if err != nil {
-			return nil, errors.WithMessage(err, fmt.Sprintf("mul[arg%d]:", i+1))
+			return nil, err
 		}
-		switch av := an.(type) {
-		case int64:
-			ival *= av
-		case float64:
+		in = append(in, fv)
+		if isFloat {
 			hasFloat = true
-			fval *= av
-		default:
-			return nil, errors.New("inconceivable")
+			fval *= fv
+		} else if p, ok := mulInt64(ival, iv); ok && !overflow {
+			ival = p
+		} else {
+			overflow = true
 		}
 	}
 
+	if overflow {
+		return f.intOverflow("mul", all, true, hasFloat, in)
+	}
 	if hasFloat {
-		return float64(ival) * fval, nil
+		return f.checkFloat("mul", float64(ival)*fval, ErrOverflow, in...)
 	}
 
 	return ival, nil
 }
 
-func max(a interface{}, args ...interface{}) (interface{}, error) {
-	ival, fval, hasFloat, err := initialArg("max", a, math.MinInt64, -math.MaxFloat64)
-	if err != nil {
-		return nil, err
+func (f *funcs) max(a interface{}, args ...interface{}) (interface{}, error) {
+	if all := append([]interface{}{a}, args...); hasQuantity(all) {
+		return f.quantityExtreme("max", all, 1)
 	}
 
-	for i, arg := range args {
-		an, err := toNumber(arg)
+	ival := int64(math.MinInt64)
+	fval := math.Inf(-1)
+	hasInt, hasFloat := false, false
+
+	for i, arg := range append([]interface{}{a}, args...) {
+		iv, fv, isFloat, err := f.numberArg("max", i, arg)
 		if err != nil {
-			return nil, errors.WithMessage(err, fmt.Sprintf("max[arg%d]:", i+1))
+			return nil, err
 		}
-		switch av := an.(type) {
-		case int64:
-			if av > ival {
-				ival = av
-			}
-		case float64:
+		if isFloat {
 			hasFloat = true
-			fval = math.Max(fval, av)
-		default:
-			return nil, errors.New("inconceivable")
+			fval = math.Max(fval, fv)
+		} else {
+			hasInt = true
+			if iv > ival {
+				ival = iv
+			}
 		}
 	}
 
-	if hasFloat {
+	// the seeds are not arguments, so only compare with ival if an integer
+	// was seen
+	if hasFloat && hasInt {
 		return math.Max(fval, float64(ival)), nil
 	}
+	if hasFloat {
+		return fval, nil
+	}
 
 	return ival, nil
 }
 
-func min(a interface{}, args ...interface{}) (interface{}, error) {
-	ival, fval, hasFloat, err := initialArg("min", a, math.MaxInt64, math.MaxFloat64)
-	if err != nil {
-		return nil, err
+func (f *funcs) min(a interface{}, args ...interface{}) (interface{}, error) {
+	if all := append([]interface{}{a}, args...); hasQuantity(all) {
+		return f.quantityExtreme("min", all, -1)
 	}
 
-	for i, arg := range args {
-		an, err := toNumber(arg)
+	ival := int64(math.MaxInt64)
+	fval := math.Inf(1)
+	hasInt, hasFloat := false, false
+
+	for i, arg := range append([]interface{}{a}, args...) {
+		iv, fv, isFloat, err := f.numberArg("min", i, arg)
 		if err != nil {
-			return nil, errors.WithMessage(err, fmt.Sprintf("min[arg%d]:", i+1))
+			return nil, err
 		}
-		switch av := an.(type) {
-		case int64:
-			if av < ival {
-				ival = av
-			}
-		case float64:
+		if isFloat {
 			hasFloat = true
-			fval = math.Min(fval, av)
-		default:
-			return nil, errors.New("inconceivable")
+			fval = math.Min(fval, fv)
+		} else {
+			hasInt = true
+			if iv < ival {
+				ival = iv
+			}
 		}
 	}
 
-	if hasFloat {
+	// the seeds are not arguments, so only compare with ival if an integer
+	// was seen
+	if hasFloat && hasInt {
 		return math.Min(fval, float64(ival)), nil
 	}
+	if hasFloat {
+		return fval, nil
+	}
 
 	return ival, nil
 }
 
-func ceil(arg interface{}) (int64, error) {
-	val, err := toFloat64(arg)
+func (f *funcs) ceil(arg interface{}) (int64, error) {
+	val, err := f.toFloat64(arg)
 	if err != nil {
-		return 0, errors.WithMessage(err, "ceil")
+		return 0, argError("ceil", 0, arg, err)
 	}
-	return int64(math.Ceil(val)), nil
+	return f.checkInt("ceil", math.Ceil(val), val)
 }
 
-func round(arg interface{}) (int64, error) {
-	val, err := toFloat64(arg)
+func (f *funcs) round(arg interface{}) (int64, error) {
+	val, err := f.toFloat64(arg)
 	if err != nil {
-		return 0, errors.WithMessage(err, "round")
+		return 0, argError("round", 0, arg, err)
 	}
-	return int64(math.Round(val)), nil
+	return f.checkInt("round", math.Round(val), val)
 }
 
 //
 // 'complex' math
 //
 
-func acos(arg interface{}) (float64, error) {
-	val, err := toFloat64(arg)
+func (f *funcs) acos(arg interface{}) (float64, error) {
+	val, err := f.toFloat64(arg)
 	if err != nil {
-		return 0, errors.WithMessage(err, "acos")
+		return 0, argError("acos", 0, arg, err)
 	}
-	return math.Acos(val), nil
+	return f.checkFloat("acos", math.Acos(val), ErrOverflow, val)
 }
 
-func acosh(arg interface{}) (float64, error) {
-	val, err := toFloat64(arg)
+func (f *funcs) acosh(arg interface{}) (float64, error) {
+	val, err := f.toFloat64(arg)
 	if err != nil {
-		return 0, errors.WithMessage(err, "acosh")
+		return 0, argError("acosh", 0, arg, err)
 	}
-	return math.Acosh(val), nil
+	return f.checkFloat("acosh", math.Acosh(val), ErrOverflow, val)
 }
 
-func asin(arg interface{}) (float64, error) {
-	val, err := toFloat64(arg)
+func (f *funcs) asin(arg interface{}) (float64, error) {
+	val, err := f.toFloat64(arg)
 	if err != nil {
-		return 0, errors.WithMessage(err, "asin")
+		return 0, argError("asin", 0, arg, err)
 	}
-	return math.Asin(val), nil
+	return f.checkFloat("asin", math.Asin(val), ErrOverflow, val)
 }
 
-func asinh(arg interface{}) (float64, error) {
-	val, err := toFloat64(arg)
+func (f *funcs) asinh(arg interface{}) (float64, error) {
+	val, err := f.toFloat64(arg)
 	if err != nil {
-		return 0, errors.WithMessage(err, "asinh")
+		return 0, argError("asinh", 0, arg, err)
 	}
-	return math.Asinh(val), nil
+	return f.checkFloat("asinh", math.Asinh(val), ErrOverflow, val)
 }
 
-func atan(arg interface{}) (float64, error) {
-	val, err := toFloat64(arg)
+func (f *funcs) atan(arg interface{}) (float64, error) {
+	val, err := f.toFloat64(arg)
 	if err != nil {
-		return 0, errors.WithMessage(err, "atan")
+		return 0, argError("atan", 0, arg, err)
 	}
-	return math.Atan(val), nil
+	return f.checkFloat("atan", math.Atan(val), ErrOverflow, val)
 }
 
-func atan2(y interface{}, x interface{}) (float64, error) {
-	yv, err := toFloat64(y)
+func (f *funcs) atan2(y interface{}, x interface{}) (float64, error) {
+	yv, err := f.toFloat64(y)
 	if err != nil {
-		return 0, errors.WithMessage(err, "atan2[y]")
+		return 0, argError("atan2", 0, y, err)
 	}
 
-	xv, err := toFloat64(x)
+	xv, err := f.toFloat64(x)
 	if err != nil {
-		return 0, errors.WithMessage(err, "atan2[x]")
+		return 0, argError("atan2", 1, x, err)
 	}
 	return math.Atan2(yv, xv), nil
 }
 
-func atanh(arg interface{}) (float64, error) {
-	val, err := toFloat64(arg)
+func (f *funcs) atanh(arg interface{}) (float64, error) {
+	val, err := f.toFloat64(arg)
 	if err != nil {
-		return 0, errors.WithMessage(err, "atanh")
+		return 0, argError("atanh", 0, arg, err)
 	}
-	return math.Atanh(val), nil
+	return f.checkFloat("atanh", math.Atanh(val), ErrDomain, val)
 }
 
-func cbrt(arg interface{}) (float64, error) {
-	val, err := toFloat64(arg)
+func (f *funcs) cbrt(arg interface{}) (float64, error) {
+	val, err := f.toFloat64(arg)
 	if err != nil {
-		return 0, errors.WithMessage(err, "cbrt")
+		return 0, argError("cbrt", 0, arg, err)
 	}
-	return math.Cbrt(val), nil
+	return f.checkFloat("cbrt", math.Cbrt(val), ErrOverflow, val)
 }
 
 // args are inverted to accomdate `computation | copysign -1`
-func copysign(x interface{}, y interface{}) (float64, error) {
+func (f *funcs) copysign(x interface{}, y interface{}) (float64, error) {
 
-	xv, err := toFloat64(x)
+	xv, err := f.toFloat64(x)
 	if err != nil {
-		return 0, errors.WithMessage(err, "copysign[x]")
+		return 0, argError("copysign", 0, x, err)
 	}
 
-	yv, err := toFloat64(y)
+	yv, err := f.toFloat64(y)
 	if err != nil {
-		return 0, errors.WithMessage(err, "copysign[y]")
+		return 0, argError("copysign", 1, y, err)
 	}
 
 	return math.Copysign(xv, yv), nil
 }
 
-func cos(arg interface{}) (float64, error) {
-	val, err := toFloat64(arg)
+func (f *funcs) cos(arg interface{}) (float64, error) {
+	val, err := f.toFloat64(arg)
 	if err != nil {
-		return 0, errors.WithMessage(err, "cos")
+		return 0, argError("cos", 0, arg, err)
 	}
-	return math.Cos(val), nil
+	return f.checkFloat("cos", math.Cos(val), ErrOverflow, val)
 }
 
-func cosh(arg interface{}) (float64, error) {
-	val, err := toFloat64(arg)
+func (f *funcs) cosh(arg interface{}) (float64, error) {
+	val, err := f.toFloat64(arg)
 	if err != nil {
-		return 0, errors.WithMessage(err, "cosh")
+		return 0, argError("cosh", 0, arg, err)
 	}
-	return math.Cosh(val), nil
+	return f.checkFloat("cosh", math.Cosh(val), ErrOverflow, val)
 }
 
-func erf(arg interface{}) (float64, error) {
-	val, err := toFloat64(arg)
+func (f *funcs) erf(arg interface{}) (float64, error) {
+	val, err := f.toFloat64(arg)
 	if err != nil {
-		return 0, errors.WithMessage(err, "erf")
+		return 0, argError("erf", 0, arg, err)
 	}
-	return math.Erf(val), nil
+	return f.checkFloat("erf", math.Erf(val), ErrOverflow, val)
 }
 
-func erfc(arg interface{}) (float64, error) {
-	val, err := toFloat64(arg)
+func (f *funcs) erfc(arg interface{}) (float64, error) {
+	val, err := f.toFloat64(arg)
 	if err != nil {
-		return 0, errors.WithMessage(err, "erfc")
+		return 0, argError("erfc", 0, arg, err)
 	}
-	return math.Erfc(val), nil
+	return f.checkFloat("erfc", math.Erfc(val), ErrOverflow, val)
 }
 
-func erfinv(arg interface{}) (float64, error) {
-	val, err := toFloat64(arg)
+func (f *funcs) erfinv(arg interface{}) (float64, error) {
+	val, err := f.toFloat64(arg)
 	if err != nil {
-		return 0, errors.WithMessage(err, "erfinv")
+		return 0, argError("erfinv", 0, arg, err)
 	}
-	return math.Erfinv(val), nil
+	return f.checkFloat("erfinv", math.Erfinv(val), ErrDomain, val)
 }
 
-func exp(arg interface{}) (float64, error) {
-	val, err := toFloat64(arg)
+func (f *funcs) exp(arg interface{}) (float64, error) {
+	val, err := f.toFloat64(arg)
 	if err != nil {
-		return 0, errors.WithMessage(err, "exp")
+		return 0, argError("exp", 0, arg, err)
 	}
-	return math.Exp(val), nil
+	return f.checkFloat("exp", math.Exp(val), ErrOverflow, val)
 }
 
-func exp2(arg interface{}) (float64, error) {
-	val, err := toFloat64(arg)
+func (f *funcs) exp2(arg interface{}) (float64, error) {
+	val, err := f.toFloat64(arg)
 	if err != nil {
-		return 0, errors.WithMessage(err, "exp2")
+		return 0, argError("exp2", 0, arg, err)
 	}
-	return math.Exp2(val), nil
+	return f.checkFloat("exp2", math.Exp2(val), ErrOverflow, val)
 }
 
-func expm1(arg interface{}) (float64, error) {
-	val, err := toFloat64(arg)
+func (f *funcs) expm1(arg interface{}) (float64, error) {
+	val, err := f.toFloat64(arg)
 	if err != nil {
-		return 0, errors.WithMessage(err, "expm1")
+		return 0, argError("expm1", 0, arg, err)
 	}
-	return math.Expm1(val), nil
+	return f.checkFloat("expm1", math.Expm1(val), ErrOverflow, val)
 }
 
-func floor(arg interface{}) (float64, error) {
-	val, err := toFloat64(arg)
+func (f *funcs) floor(arg interface{}) (float64, error) {
+	val, err := f.toFloat64(arg)
 	if err != nil {
-		return 0, errors.WithMessage(err, "floor")
+		return 0, argError("floor", 0, arg, err)
 	}
-	return math.Floor(val), nil
+	return f.checkFloat("floor", math.Floor(val), ErrOverflow, val)
 }
 
-func gamma(arg interface{}) (float64, error) {
-	val, err := toFloat64(arg)
+func (f *funcs) gamma(arg interface{}) (float64, error) {
+	val, err := f.toFloat64(arg)
 	if err != nil {
-		return 0, errors.WithMessage(err, "gammahypot")
+		return 0, argError("gamma", 0, arg, err)
 	}
-	return math.Gamma(val), nil
+	// gamma has poles at zero and the negative integers
+	if val <= 0 && val == math.Trunc(val) {
+		return f.checkFloat("gamma", math.Gamma(val), ErrDomain, val)
+	}
+	return f.checkFloat("gamma", math.Gamma(val), ErrOverflow, val)
 }
 
-func hypot(p interface{}, q interface{}) (float64, error) {
+func (f *funcs) hypot(p interface{}, q interface{}) (float64, error) {
 
-	pv, err := toFloat64(p)
+	pv, err := f.toFloat64(p)
 	if err != nil {
-		return 0, errors.WithMessage(err, "copysign[p]")
+		return 0, argError("hypot", 0, p, err)
 	}
 
-	qv, err := toFloat64(q)
+	qv, err := f.toFloat64(q)
 	if err != nil {
-		return 0, errors.WithMessage(err, "copysign[q]")
+		return 0, argError("hypot", 1, q, err)
 	}
 
-	return math.Hypot(pv, qv), nil
+	return f.checkFloat("hypot", math.Hypot(pv, qv), ErrOverflow, pv, qv)
 }
 
-func ilogb(arg interface{}) (int, error) {
-	val, err := toFloat64(arg)
+func (f *funcs) ilogb(arg interface{}) (int, error) {
+	val, err := f.toFloat64(arg)
 	if err != nil {
-		return 0, errors.WithMessage(err, "ilogb")
+		return 0, argError("ilogb", 0, arg, err)
+	}
+	if _, err := f.checkFloat("ilogb", math.Logb(val), ErrDomain, val); err != nil {
+		return 0, err
 	}
 	return math.Ilogb(val), nil
 }
 
-func inf(arg interface{}) (float64, error) {
-	val, err := toInt(arg)
+func (f *funcs) inf(arg interface{}) (float64, error) {
+	val, err := f.toInt(arg)
 	if err != nil {
-		return 0, errors.WithMessage(err, "inf")
+		return 0, argError("inf", 0, arg, err)
 	}
 	return math.Inf(val), nil
 }
 
-func log(arg interface{}) (float64, error) {
-	val, err := toFloat64(arg)
+func (f *funcs) log(arg interface{}) (float64, error) {
+	val, err := f.toFloat64(arg)
 	if err != nil {
-		return 0, errors.WithMessage(err, "log")
+		return 0, argError("log", 0, arg, err)
 	}
-	return math.Log(val), nil
+	return f.checkFloat("log", math.Log(val), ErrDomain, val)
 }
 
-func log10(arg interface{}) (float64, error) {
-	val, err := toFloat64(arg)
+func (f *funcs) log10(arg interface{}) (float64, error) {
+	val, err := f.toFloat64(arg)
 	if err != nil {
-		return 0, errors.WithMessage(err, "log10")
+		return 0, argError("log10", 0, arg, err)
 	}
-	return math.Log10(val), nil
+	return f.checkFloat("log10", math.Log10(val), ErrDomain, val)
 }
 
-func log1p(arg interface{}) (float64, error) {
-	val, err := toFloat64(arg)
+func (f *funcs) log1p(arg interface{}) (float64, error) {
+	val, err := f.toFloat64(arg)
 	if err != nil {
-		return 0, errors.WithMessage(err, "log1p")
+		return 0, argError("log1p", 0, arg, err)
 	}
-	return math.Log1p(val), nil
+	return f.checkFloat("log1p", math.Log1p(val), ErrDomain, val)
 }
 
-func log2(arg interface{}) (float64, error) {
-	val, err := toFloat64(arg)
+func (f *funcs) log2(arg interface{}) (float64, error) {
+	val, err := f.toFloat64(arg)
 	if err != nil {
-		return 0, errors.WithMessage(err, "log2")
+		return 0, argError("log2", 0, arg, err)
 	}
-	return math.Log2(val), nil
+	return f.checkFloat("log2", math.Log2(val), ErrDomain, val)
 }
 
-func logb(arg interface{}) (float64, error) {
-	val, err := toFloat64(arg)
+func (f *funcs) logb(arg interface{}) (float64, error) {
+	val, err := f.toFloat64(arg)
 	if err != nil {
-		return 0, errors.WithMessage(err, "logb")
+		return 0, argError("logb", 0, arg, err)
 	}
-	return math.Logb(val), nil
+	return f.checkFloat("logb", math.Logb(val), ErrDomain, val)
 }
 
-func pow(x interface{}, y interface{}) (float64, error) {
-	xv, err := toFloat64(x)
+func (f *funcs) pow(x interface{}, y interface{}) (float64, error) {
+	xv, err := f.toFloat64(x)
 	if err != nil {
-		return 0, errors.WithMessage(err, "copysign[x]")
+		return 0, argError("pow", 0, x, err)
 	}
 
-	yv, err := toFloat64(y)
+	yv, err := f.toFloat64(y)
 	if err != nil {
-		return 0, errors.WithMessage(err, "copysign[y]")
+		return 0, argError("pow", 1, y, err)
+	}
+	// pow has a pole when raising zero to a negative power
+	if xv == 0 && yv < 0 {
+		return f.checkFloat("pow", math.Pow(xv, yv), ErrDomain, xv, yv)
 	}
-	return math.Pow(xv, yv), nil
+	return f.checkFloat("pow", math.Pow(xv, yv), ErrOverflow, xv, yv)
 }
 
-func pow10(arg interface{}) (float64, error) {
-	val, err := toInt(arg)
+func (f *funcs) pow10(arg interface{}) (float64, error) {
+	val, err := f.toInt(arg)
 	if err != nil {
-		return 0, errors.WithMessage(err, "signbit")
+		return 0, argError("pow10", 0, arg, err)
 	}
-	return math.Pow10(val), nil
+	return f.checkFloat("pow10", math.Pow10(val), ErrOverflow, float64(val))
 }
 
-func signbit(arg interface{}) (bool, error) {
-	val, err := toFloat64(arg)
+func (f *funcs) signbit(arg interface{}) (bool, error) {
+	val, err := f.toFloat64(arg)
 	if err != nil {
-		return false, errors.WithMessage(err, "signbit")
+		return false, argError("signbit", 0, arg, err)
 	}
 	return math.Signbit(val), nil
 }
 
-func sin(arg interface{}) (float64, error) {
-	val, err := toFloat64(arg)
+func (f *funcs) sin(arg interface{}) (float64, error) {
+	val, err := f.toFloat64(arg)
 	if err != nil {
-		return 0, errors.WithMessage(err, "sin")
+		return 0, argError("sin", 0, arg, err)
 	}
-	return math.Sin(val), nil
+	return f.checkFloat("sin", math.Sin(val), ErrOverflow, val)
 }
 
-func sinh(arg interface{}) (float64, error) {
-	val, err := toFloat64(arg)
+func (f *funcs) sinh(arg interface{}) (float64, error) {
+	val, err := f.toFloat64(arg)
 	if err != nil {
-		return 0, errors.WithMessage(err, "sinh")
+		return 0, argError("sinh", 0, arg, err)
 	}
-	return math.Sinh(val), nil
+	return f.checkFloat("sinh", math.Sinh(val), ErrOverflow, val)
 }
 
-func sqrt(arg interface{}) (float64, error) {
-	val, err := toFloat64(arg)
+func (f *funcs) sqrt(arg interface{}) (float64, error) {
+	val, err := f.toFloat64(arg)
 	if err != nil {
-		return 0, errors.WithMessage(err, "sqrt")
+		return 0, argError("sqrt", 0, arg, err)
 	}
-	return math.Sqrt(val), nil
+	return f.checkFloat("sqrt", math.Sqrt(val), ErrOverflow, val)
 }
 
-func tan(arg interface{}) (float64, error) {
-	val, err := toFloat64(arg)
+func (f *funcs) tan(arg interface{}) (float64, error) {
+	val, err := f.toFloat64(arg)
 	if err != nil {
-		return 0, errors.WithMessage(err, "tan")
+		return 0, argError("tan", 0, arg, err)
 	}
-	return math.Tan(val), nil
+	return f.checkFloat("tan", math.Tan(val), ErrOverflow, val)
 }
 
-func tanh(arg interface{}) (float64, error) {
-	val, err := toFloat64(arg)
+func (f *funcs) tanh(arg interface{}) (float64, error) {
+	val, err := f.toFloat64(arg)
 	if err != nil {
-		return 0, errors.WithMessage(err, "tanh")
+		return 0, argError("tanh", 0, arg, err)
 	}
-	return math.Tanh(val), nil
+	return f.checkFloat("tanh", math.Tanh(val), ErrOverflow, val)
 }
 
-func trunc(arg interface{}) (float64, error) {
-	val, err := toFloat64(arg)
+func (f *funcs) trunc(arg interface{}) (float64, error) {
+	val, err := f.toFloat64(arg)
 	if err != nil {
-		return 0, errors.WithMessage(err, "trunc")
+		return 0, argError("trunc", 0, arg, err)
 	}
-	return math.Trunc(val), nil
+	return f.checkFloat("trunc", math.Trunc(val), ErrOverflow, val)
 }
 
 // extras
 
-func degrees(arg interface{}) (float64, error) {
-	rads, err := toFloat64(arg)
+func (f *funcs) degrees(arg interface{}) (float64, error) {
+	rads, err := f.toFloat64(arg)
 	if err != nil {
-		return 0, errors.WithMessage(err, "degrees")
+		return 0, argError("degrees", 0, arg, err)
 	}
-	return rads * (180.0 / math.Pi), nil
+	return f.checkFloat("degrees", rads*(180.0/math.Pi), ErrOverflow, rads)
 }
 
-func radians(arg interface{}) (float64, error) {
-	degs, err := toFloat64(arg)
+func (f *funcs) radians(arg interface{}) (float64, error) {
+	degs, err := f.toFloat64(arg)
 	if err != nil {
-		return 0, errors.WithMessage(err, "radians")
+		return 0, argError("radians", 0, arg, err)
 	}
-	return degs * (math.Pi / 180.0), nil
+	return f.checkFloat("radians", degs*(math.Pi/180.0), ErrOverflow, degs)
 }
@@ -1,7 +1,10 @@
 package sprigmath
 
 import (
+	"math"
 	"testing"
+
+	"github.com/pkg/errors"
 )
 
 func TestAdd(t *testing.T) {
@@ -31,6 +34,11 @@ func TestBiggest(t *testing.T) {
 	if err := runt(tpl, `345.7`); err != nil {
 		t.Error(err)
 	}
+
+	tpl = `{{ max -1e20 -2e20 }} {{ max (inf -1) }}`
+	if err := runt(tpl, `-1e+20 -Inf`); err != nil {
+		t.Error(err)
+	}
 }
 func TestMin(t *testing.T) {
 	tpl := `{{ min 1 2 3 345 5 6 7}}`
@@ -52,6 +60,11 @@ func TestMin(t *testing.T) {
 	if err := runt(tpl, `1.2`); err != nil {
 		t.Error(err)
 	}
+
+	tpl = `{{ min 1e20 2e20 }} {{ min (inf 1) }}`
+	if err := runt(tpl, `1e+20 +Inf`); err != nil {
+		t.Error(err)
+	}
 }
 
 func TestMisc(t *testing.T) {
@@ -92,3 +105,65 @@ func TestMul(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestStrict(t *testing.T) {
+	strict := Options{Strict: true}
+
+	for _, tc := range []struct {
+		tpl   string
+		cause error
+		msg   string
+	}{
+		{`{{ sqrt -1 }}`, ErrDomain, "sqrt[arg0]: -1 is outside of the domain"},
+		{`{{ log 0 }}`, ErrDomain, "log[arg0]: 0 is outside of the domain"},
+		{`{{ acos 2 }}`, ErrDomain, "acos[arg0]: 2 is outside of the domain"},
+		{`{{ gamma -1 }}`, ErrDomain, "gamma[arg0]: -1 is outside of the domain"},
+		{`{{ pow 0 -1 }}`, ErrDomain, "pow: [0 -1] is outside of the domain"},
+		{`{{ exp 1000 }}`, ErrOverflow, "exp[arg0]: result overflows for 1000"},
+		{`{{ mul 1e300 1e300 }}`, ErrOverflow, "mul: result overflows for [1e+300 1e+300]"},
+		{`{{ div 1 0.0 }}`, ErrDivideByZero, "div[arg1]: division by zero"},
+		{`{{ mod 1.5 0 }}`, ErrDivideByZero, "mod[arg1]: division by zero"},
+		{`{{ ceil 1e300 }}`, ErrOverflow, "ceil[arg0]: 1e+300 overflows int64"},
+		{`{{ mul 9223372036854775807 2 }}`, ErrOverflow, "mul: result overflows int64 for [9223372036854775807 2]"},
+		{`{{ add 9223372036854775807 1 }}`, ErrOverflow, "add: result overflows int64 for [9223372036854775807 1]"},
+		{`{{ sub -9223372036854775808 1 }}`, ErrOverflow, "sub: result overflows int64 for [-9223372036854775808 1]"},
+	} {
+		_, err := runOpts(strict, tc.tpl, nil)
+		if !errors.Is(err, tc.cause) {
+			t.Errorf("%s: expected %v, got %v", tc.tpl, tc.cause, err)
+		} else if err = testError(tc.msg, err); err != nil {
+			t.Errorf("%s: %v", tc.tpl, err)
+		}
+	}
+
+	// non-finite values that were asked for pass through
+	if out, err := runOpts(strict, `{{ inf 1 | sqrt }}`, nil); err != nil || out != "+Inf" {
+		t.Errorf("Expected +Inf, got %q %v", out, err)
+	}
+
+	// integer results that overflow an int64 along the way are exact if the
+	// result fits, and otherwise a float in lenient mode
+	vars := map[string]interface{}{"big": 3e18, "max": int64(math.MaxInt64)}
+	for _, tc := range []struct {
+		opts     Options
+		tpl      string
+		expected string
+	}{
+		{Options{}, `{{ mul 9223372036854775807 2 }}`, "1.8446744073709552e+19"},
+		{Options{}, `{{ add .max 1 }}`, "9.223372036854776e+18"},
+		{Options{}, `{{ sub -9223372036854775808 9223372036854775807 }}`, "-1.8446744073709552e+19"},
+		{Options{}, `{{ mul .max 2 0 }}`, "0"},
+		{strict, `{{ add .max 1 -2 }}`, "9223372036854775806"},
+		{Options{IntegralFloats: true}, `{{ mul .big .big }}`, "9e+36"},
+		{Options{IntegralFloats: true}, `{{ mul .big 3 }} {{ mul .big 4 }}`, "9000000000000000000 1.2e+19"},
+	} {
+		if out, err := runOpts(tc.opts, tc.tpl, vars); err != nil || out != tc.expected {
+			t.Errorf("%s: expected %q, got %q %v", tc.tpl, tc.expected, out, err)
+		}
+	}
+
+	// lenient mode keeps IEEE 754 results
+	if err := runt(`{{ sqrt -1 }} {{ log 0 }} {{ div 1 0.0 }}`, "NaN -Inf +Inf"); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,307 @@
+package sprigmath
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/sprig"
+)
+
+// FuncInfo describes a template function, for generating documentation and
+// editor completion
+type FuncInfo struct {
+	Name string `json:"name"`
+
+	// Signature is how the function is called, such as
+	// "formatNumber locale [options] x"
+	Signature string `json:"signature"`
+
+	// Args are the names of the arguments. Optional arguments are in
+	// brackets, and variadic ones end with "...".
+	Args []string `json:"args"`
+
+	// Returns is the kind of value returned: "int", "float", "number" (an
+	// int or a float), "bool", "string", "any", or the name of one of the
+	// types in this package, such as "Decimal" or "[]Decimal". A "float" is
+	// a Float or a similar type when Options.FloatFormat is set.
+	Returns string `json:"returns"`
+
+	Description string    `json:"description"`
+	Examples    []Example `json:"examples,omitempty"`
+
+	// OverridesSprig is true for functions that replace a sprig function of
+	// the same name
+	OverridesSprig bool `json:"overridesSprig"`
+}
+
+// Example is a template using a function, and what it renders with the
+// default options
+type Example struct {
+	Template string `json:"template"`
+	Output   string `json:"output"`
+}
+
+// sprigFuncs are sprig's generic functions, which those of the same name in
+// this library replace
+var sprigFuncs = sprig.GenericFuncMap()
+
+// info makes the FuncInfo for a built in function. args is a space separated
+// list, and examples alternate between a template and its output.
+func info(name, args, returns, description string, examples ...string) FuncInfo {
+	fi := FuncInfo{Name: name, Returns: returns, Description: description}
+	if args != "" {
+		fi.Args = strings.Fields(args)
+	}
+	for i := 0; i+1 < len(examples); i += 2 {
+		fi.Examples = append(fi.Examples, Example{examples[i], examples[i+1]})
+	}
+	return fi
+}
+
+// builtinInfo describes every function returned by funcs.functions. Returns
+// is only given where it cannot be worked out from the Go signature.
+var builtinInfo = []FuncInfo{
+	// conversions
+	info("atoi", "s", "", "Parses a decimal integer.", `{{ atoi "42" }}`, "42"),
+	info("int", "x", "", "Converts x to an int, truncating it unless in strict mode.", `{{ int "42" }}`, "42"),
+	info("int64", "x", "", "Converts x to an int64, truncating it unless in strict mode.", `{{ int64 "1e3" }}`, "1000"),
+	info("int8", "x", "", "Converts x to an int8, rejecting fractions and values out of range.", `{{ int8 127 }}`, "127"),
+	info("int16", "x", "", "Converts x to an int16, rejecting fractions and values out of range.", `{{ int16 -300 }}`, "-300"),
+	info("int32", "x", "", "Converts x to an int32, rejecting fractions and values out of range.", `{{ int32 "70000" }}`, "70000"),
+	info("uint8", "x", "", "Converts x to a uint8, rejecting fractions and values out of range.", `{{ uint8 255 }}`, "255"),
+	info("uint16", "x", "", "Converts x to a uint16, rejecting fractions and values out of range.", `{{ uint16 "0x10" }}`, "16"),
+	info("uint32", "x", "", "Converts x to a uint32, rejecting fractions and values out of range.", `{{ uint32 4e9 }}`, "4000000000"),
+	info("uint64", "x", "", "Converts x to a uint64, rejecting fractions and values out of range.", `{{ uint64 "18446744073709551615" }}`, "18446744073709551615"),
+	info("float64", "x", "", "Converts x to a float64.", `{{ float64 "2.5" }}`, "2.5"),
+	info("double", "x", "", "Converts x to a float64, like float64.", `{{ double 3 }}`, "3"),
+	info("number", "x", "number", "Converts x to an int64, or a float64 if it is not a whole number.", `{{ number "1e3" }}`, "1000", `{{ number "2.5" }}`, "2.5"),
+
+	// arithmetic
+	info("add1", "x", "number", "Adds one to x.", `{{ add1 41 }}`, "42"),
+	info("add", "a b...", "number", "Adds numbers. The result is an int if they are all integers.", `{{ add 1 2 3 }}`, "6", `{{ add 1 2.5 }}`, "3.5"),
+	info("sub", "a b", "number", "Subtracts b from a.", `{{ sub 10 4 }}`, "6"),
+	info("mul", "a b...", "number", "Multiplies numbers. The result is an int if they are all integers.", `{{ mul 2 3 4 }}`, "24"),
+	info("div", "a b", "number", "Divides a by b. The result is a float64, even when both are integers.", `{{ div 3 2 }}`, "1.5"),
+	info("mod", "a b", "number", "The remainder of dividing a by b.", `{{ mod 7 3 }}`, "1"),
+	info("max", "a b...", "number", "The largest of the numbers.", `{{ max 1 7 3 }}`, "7"),
+	info("biggest", "a b...", "number", "The largest of the numbers, like max.", `{{ biggest 1 7.5 3 }}`, "7.5"),
+	info("min", "a b...", "number", "The smallest of the numbers.", `{{ min 4 2 9 }}`, "2"),
+	info("ceil", "x", "", "Rounds x up to an integer.", `{{ ceil 1.2 }}`, "2"),
+	info("floor", "x", "", "Rounds x down to a whole number.", `{{ floor 1.8 }}`, "1"),
+	info("round", "x", "", "Rounds x to the nearest integer, with halves away from zero.", `{{ round 2.5 }}`, "3"),
+	info("trunc", "x", "", "Drops the fractional part of x.", `{{ trunc -2.7 }}`, "-2"),
+
+	// math
+	info("acos", "x", "", "The arccosine of x, in radians.", `{{ acos 1 }}`, "0"),
+	info("acosh", "x", "", "The inverse hyperbolic cosine of x.", `{{ acosh 1 }}`, "0"),
+	info("asin", "x", "", "The arcsine of x, in radians.", `{{ asin 0 }}`, "0"),
+	info("asinh", "x", "", "The inverse hyperbolic sine of x.", `{{ asinh 0 }}`, "0"),
+	info("atan", "x", "", "The arctangent of x, in radians.", `{{ atan 0 }}`, "0"),
+	info("atan2", "y x", "", "The arctangent of y/x, using the signs of both to find the quadrant.", `{{ atan2 0 1 }}`, "0"),
+	info("atanh", "x", "", "The inverse hyperbolic tangent of x.", `{{ atanh 0 }}`, "0"),
+	info("cbrt", "x", "", "The cube root of x.", `{{ cbrt 27 }}`, "3"),
+	info("copysign", "x y", "", "x with the sign of y.", `{{ copysign 3 -1 }}`, "-3"),
+	info("cos", "x", "", "The cosine of x radians.", `{{ cos 0 }}`, "1"),
+	info("cosh", "x", "", "The hyperbolic cosine of x.", `{{ cosh 0 }}`, "1"),
+	info("degrees", "x", "", "Converts x radians to degrees.", `{{ degrees pi }}`, "180"),
+	info("erf", "x", "", "The error function of x.", `{{ erf 0 }}`, "0"),
+	info("erfc", "x", "", "The complementary error function of x.", `{{ erfc 0 }}`, "1"),
+	info("erfinv", "x", "", "The inverse error function of x.", `{{ erfinv 0 }}`, "0"),
+	info("exp", "x", "", "e to the power of x.", `{{ exp 0 }}`, "1"),
+	info("exp2", "x", "", "2 to the power of x.", `{{ exp2 10 }}`, "1024"),
+	info("expm1", "x", "", "e to the power of x, minus one, accurate for x near zero.", `{{ expm1 0 }}`, "0"),
+	info("gamma", "x", "", "The gamma function of x.", `{{ gamma 5 }}`, "24"),
+	info("hypot", "p q", "", "The square root of p*p + q*q.", `{{ hypot 3 4 }}`, "5"),
+	info("ilogb", "x", "", "The binary exponent of x as an integer.", `{{ ilogb 8 }}`, "3"),
+	info("inf", "sign", "", "Positive infinity if sign >= 0, and negative infinity otherwise.", `{{ inf 1 }}`, "+Inf"),
+	info("log", "x", "", "The natural logarithm of x.", `{{ log 1 }}`, "0"),
+	info("log10", "x", "", "The base 10 logarithm of x.", `{{ log10 1000 }}`, "3"),
+	info("log1p", "x", "", "The natural logarithm of 1 + x, accurate for x near zero.", `{{ log1p 0 }}`, "0"),
+	info("log2", "x", "", "The base 2 logarithm of x.", `{{ log2 8 }}`, "3"),
+	info("logb", "x", "", "The binary exponent of x.", `{{ logb 8 }}`, "3"),
+	info("pow", "x y", "", "x to the power of y.", `{{ pow 2 10 }}`, "1024"),
+	info("pow10", "n", "", "10 to the power of n.", `{{ pow10 3 }}`, "1000"),
+	info("radians", "x", "", "Converts x degrees to radians.", `{{ radians 0 }}`, "0"),
+	info("signbit", "x", "", "Whether x is negative or negative zero.", `{{ signbit -2 }}`, "true"),
+	info("sin", "x", "", "The sine of x radians.", `{{ sin 0 }}`, "0"),
+	info("sinh", "x", "", "The hyperbolic sine of x.", `{{ sinh 0 }}`, "0"),
+	info("sqrt", "x", "", "The square root of x.", `{{ sqrt 16 }}`, "4"),
+	info("tan", "x", "", "The tangent of x radians.", `{{ tan 0 }}`, "0"),
+	info("tanh", "x", "", "The hyperbolic tangent of x.", `{{ tanh 0 }}`, "0"),
+	info("pi", "", "", "The constant pi.", `{{ pi }}`, "3.141592653589793"),
+	info("e", "", "", "The constant e.", `{{ e }}`, "2.718281828459045"),
+
+	// comparisons
+	info("numEq", "a b", "", "Whether a and b are the same number, whatever their types.", `{{ numEq 3 "3.0" }}`, "true"),
+	info("numLt", "a b", "", "Whether a < b.", `{{ numLt 2 2.5 }}`, "true"),
+	info("numLe", "a b", "", "Whether a <= b.", `{{ numLe 3 3.0 }}`, "true"),
+	info("numGt", "a b", "", "Whether a > b.", `{{ numGt "10" 9.99 }}`, "true"),
+	info("numGe", "a b", "", "Whether a >= b.", `{{ numGe 1 2 }}`, "false"),
+	info("between", "lo hi x", "", "Whether lo <= x <= hi.", `{{ 5 | between 1 10 }}`, "true"),
+	info("approxEq", "tolerance a b", "", `Whether a and b differ by at most tolerance, which may be a dict with "abs", "rel" and "ulps".`, `{{ approxEq 0.01 1.0 1.005 }}`, "true", `{{ approxEq (dict "rel" 0.01) 1000 1009 }}`, "true"),
+
+	// predicates
+	info("isNumber", "x", "", "Whether x can be converted to a number.", `{{ isNumber "1.5" }}`, "true", `{{ isNumber "abc" }}`, "false"),
+	info("isInt", "x", "", "Whether x is a whole number in the int64 range.", `{{ isInt 3.0 }}`, "true"),
+	info("isFloat", "x", "", "Whether x converts to a float rather than an integer.", `{{ isFloat "1.0" }}`, "true"),
+	info("isFinite", "x", "", "Whether x is a number other than an infinity or NaN.", `{{ isFinite (inf 1) }}`, "false"),
+	info("isNaN", "x", "", "Whether x is NaN.", `{{ isNaN 1 }}`, "false"),
+	info("isInf", "x", "", "Whether x is an infinity.", `{{ isInf (inf -1) }}`, "true"),
+	info("isPositive", "x", "", "Whether x > 0.", `{{ isPositive 0 }}`, "false"),
+	info("isEven", "x", "", "Whether x is an even whole number.", `{{ isEven 4 }}`, "true"),
+	info("isOdd", "x", "", "Whether x is an odd whole number.", `{{ isOdd 4 }}`, "false"),
+	info("inRange", "lo hi x", "", "Whether lo <= x <= hi, and false if any is not a number.", `{{ "x" | inRange 1 10 }}`, "false"),
+
+	// assertions
+	info("assertRange", "name lo hi x", "any", "Returns x if lo <= x <= hi, and fails otherwise.", `{{ 3 | assertRange "replicas" 1 100 }}`, "3"),
+	info("assertPositive", "name x", "any", "Returns x if x > 0, and fails otherwise.", `{{ assertPositive "timeout" 2 }}`, "2"),
+	info("assertInt", "name x", "any", "Returns x if it is a whole number, and fails otherwise.", `{{ assertInt "replicas" 3.0 }}`, "3"),
+	info("assertMultipleOf", "name m x", "any", "Returns x if it is a whole multiple of m, and fails otherwise.", `{{ assertMultipleOf "memory" 4 1024 }}`, "1024"),
+
+	// error handling
+	info("try", "name args... fallback", "any", "Calls the function name with args, and returns fallback if it fails.", `{{ try "sqrt" "abc" 0 }}`, "0"),
+
+	// percentages
+	info("percent", "x", "", `Converts a percentage such as "15%" to a fraction.`, `{{ percent "15%" }}`, "0.15"),
+	info("formatPercent", "decimals x", "", "Formats a fraction as a percentage.", `{{ formatPercent 1 0.1234 }}`, "12.3%"),
+	info("percentOf", "pct x", "", "pct percent of x.", `{{ 200 | percentOf "15%" }}`, "30"),
+	info("percentChange", "old new", "", "The change from old to new, in percent.", `{{ percentChange 80 100 }}`, "25"),
+	info("ratio", "a b", "", "a divided by b, as a float.", `{{ ratio 1 4 }}`, "0.25"),
+
+	// SI prefixes and units
+	info("parseSI", "[unit] text", "", `Parses a value with an SI prefix or RKM code, such as "4.7k" or "4k7".`, `{{ parseSI "4k7" }}`, "4700", `{{ parseSI "F" "100nF" }}`, "0.0000001"),
+	info("convert", "x from to", "", "Converts x between units of the same dimension.", `{{ convert 1 "mi" "km" }}`, "1.609344"),
+	info("quantity", "x unit", "", "Makes a Quantity that keeps its unit through add, sub, mul and div.", `{{ div (quantity 100 "km") (quantity 2 "h") }}`, "50 km/h"),
+	info("toUnit", "unit q", "", "Converts a Quantity to another unit.", `{{ quantity 1 "km" | toUnit "m" }}`, "1000 m"),
+
+	// notation
+	info("sci", "digits x", "", "Writes x in scientific notation with digits significant digits.", `{{ sci 3 0.0000047 }}`, "4.70e-6"),
+	info("sciUnicode", "digits x", "", "Like sci, with a Unicode superscript exponent.", `{{ sciUnicode 3 0.0000047 }}`, "4.70×10⁻⁶"),
+	info("eng", "digits x", "", "Writes x with an exponent that is a multiple of 3, as an SI prefix.", `{{ eng 3 0.0000047 }}`, "4.70 µ"),
+	info("engExp", "digits x", "", "Like eng, with the exponent written out.", `{{ engExp 3 0.0000047 }}`, "4.70e-6"),
+	info("engUnicode", "digits x", "", "Like eng, with a Unicode superscript exponent.", `{{ engUnicode 3 0.0000047 }}`, "4.70×10⁻⁶"),
+
+	// fractions
+	info("toFraction", "maxDenominator x", "", "The closest fraction to x with a denominator of at most maxDenominator.", `{{ toFraction 1000 3.14159 }}`, "355/113"),
+	info("formatFraction", "x", "", "Writes x as a fraction.", `{{ formatFraction 1.75 }}`, "7/4"),
+	info("formatMixed", "x", "", "Writes x as a whole number and a fraction.", `{{ formatMixed 1.75 }}`, "1 3/4"),
+
+	// words
+	info("toWords", "lang x", "", "Writes an integer in words.", `{{ toWords "en" 123 }}`, "one hundred twenty-three"),
+	info("ordinal", "[lang] x", "", "Writes an integer as an ordinal, such as 21st.", `{{ ordinal 21 }}`, "21st"),
+	info("toRoman", "x", "", "Writes an integer from 1 to 3999 in Roman numerals.", `{{ toRoman 2026 }}`, "MMXXVI"),
+	info("fromRoman", "s", "", "Parses Roman numerals.", `{{ fromRoman "MMXXVI" }}`, "2026"),
+
+	// locales
+	info("formatNumber", "locale [options] x", "", `Writes x as it is written in a locale. options is a dict with "decimals", "grouping" and "minDigits".`, `{{ formatNumber "de-DE" 1234567.89 }}`, "1.234.567,89"),
+	info("parseNumber", "locale s", "number", "Parses a number written in a locale.", `{{ parseNumber "de-DE" "1.234,5" }}`, "1234.5"),
+
+	// currencies
+	info("formatCurrency", "code locale amount", "", "Writes an amount in an ISO 4217 currency as written in a locale.", `{{ formatCurrency "EUR" "de-DE" 1234.5 }}`, "1.234,50 €"),
+	info("roundCurrency", "code amount", "", "Rounds an amount to the minor units of an ISO 4217 currency.", `{{ roundCurrency "JPY" 1234.5 }}`, "1235"),
+	info("allocate", "total n", "", "Splits total into n exact parts that add up to it.", `{{ allocate "100.00" 3 }}`, "[33.34 33.33 33.33]"),
+	info("allocateRatios", "total ratios", "", "Splits total into exact parts in proportion to ratios.", `{{ allocateRatios "100.00" (list 1 2) }}`, "[33.33 66.67]"),
+}
+
+// returnKind describes the first result of a template function
+func returnKind(fn interface{}) string {
+	ft := reflect.TypeOf(fn)
+	if ft.NumOut() == 0 {
+		return ""
+	}
+	t := ft.Out(0)
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "int"
+	case reflect.Float32, reflect.Float64:
+		return "float"
+	case reflect.Bool:
+		return "bool"
+	case reflect.String:
+		return "string"
+	case reflect.Interface:
+		return "any"
+	}
+	return strings.Replace(t.String(), "sprigmath.", "", -1)
+}
+
+// complete fills in the parts of fi that come from the function itself,
+// where they are empty
+func complete(fi FuncInfo, fn interface{}) FuncInfo {
+	if fi.Returns == "" {
+		fi.Returns = returnKind(fn)
+	}
+	if fi.Args == nil {
+		fi.Args = []string{}
+	}
+	if fi.Signature == "" {
+		fi.Signature = strings.Join(append([]string{fi.Name}, fi.Args...), " ")
+	}
+	if !fi.OverridesSprig {
+		_, fi.OverridesSprig = sprigFuncs[fi.Name]
+	}
+	return fi
+}
+
+// Describe sets the documentation of a function registered with m.
+// Signature, Returns and OverridesSprig are filled in if they are empty.
+func (m *Math) Describe(fi FuncInfo) {
+	m.info[fi.Name] = fi
+}
+
+// Functions describes the functions that this library adds to a FuncMap,
+// including those registered with m, sorted by name
+func (m *Math) Functions() []FuncInfo {
+	fns := m.f.functions()
+
+	var infos []FuncInfo
+	for _, fi := range builtinInfo {
+		infos = append(infos, complete(fi, fns[fi.Name]))
+	}
+
+	for name, fn := range m.custom {
+		fi, ok := m.info[name]
+		if !ok {
+			fi = FuncInfo{Name: name}
+			for i := 0; i < reflect.TypeOf(fn).NumIn(); i++ {
+				fi.Args = append(fi.Args, "arg"+strconv.Itoa(i))
+			}
+			if reflect.TypeOf(fn).IsVariadic() {
+				fi.Args[len(fi.Args)-1] += "..."
+			}
+		}
+		fi = complete(fi, fn)
+
+		// custom functions replace built in ones of the same name
+		for i := range infos {
+			if infos[i].Name == name {
+				infos = append(infos[:i], infos[i+1:]...)
+				break
+			}
+		}
+		infos = append(infos, fi)
+	}
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// FunctionsJSON returns Functions as JSON
+func (m *Math) FunctionsJSON() ([]byte, error) {
+	return json.MarshalIndent(m.Functions(), "", "  ")
+}
+
+// Functions describes the functions that this library adds to sprig's,
+// sorted by name
+func Functions() []FuncInfo {
+	return defaultMath.Functions()
+}
+
+// FunctionsJSON returns Functions as JSON
+func FunctionsJSON() ([]byte, error) {
+	return defaultMath.FunctionsJSON()
+}
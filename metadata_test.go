@@ -0,0 +1,174 @@
+package sprigmath
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/Masterminds/sprig"
+)
+
+func TestFunctionsComplete(t *testing.T) {
+	fns := (&funcs{}).functions()
+	infos := Functions()
+
+	seen := map[string]bool{}
+	for _, fi := range infos {
+		if seen[fi.Name] {
+			t.Errorf("%s is described twice", fi.Name)
+		}
+		seen[fi.Name] = true
+
+		if _, ok := fns[fi.Name]; !ok {
+			t.Errorf("%s is described but is not a function", fi.Name)
+		}
+		if fi.Description == "" || len(fi.Examples) == 0 {
+			t.Errorf("%s needs a description and an example", fi.Name)
+		}
+		if fi.Returns == "" {
+			t.Errorf("%s has no return kind", fi.Name)
+		}
+	}
+
+	for name := range fns {
+		if !seen[name] {
+			t.Errorf("%s is not described", name)
+		}
+	}
+}
+
+func TestFunctionsExamples(t *testing.T) {
+	for _, fi := range Functions() {
+		for _, ex := range fi.Examples {
+			if err := runt(ex.Template, ex.Output); err != nil {
+				t.Errorf("%s: %v", fi.Name, err)
+			}
+		}
+	}
+}
+
+func TestFunctionsInfo(t *testing.T) {
+	byName := map[string]FuncInfo{}
+	for _, fi := range Functions() {
+		byName[fi.Name] = fi
+	}
+
+	for name, expected := range map[string]FuncInfo{
+		"formatNumber": {Signature: "formatNumber locale [options] x", Returns: "string"},
+		"add":          {Signature: "add a b...", Returns: "number", OverridesSprig: true},
+		"sqrt":         {Signature: "sqrt x", Returns: "float"},
+		"round":        {Signature: "round x", Returns: "int", OverridesSprig: true},
+		"isNaN":        {Signature: "isNaN x", Returns: "bool"},
+		"allocate":     {Signature: "allocate total n", Returns: "[]Decimal"},
+		"pi":           {Signature: "pi", Returns: "float"},
+	} {
+		fi := byName[name]
+		if fi.Signature != expected.Signature || fi.Returns != expected.Returns || fi.OverridesSprig != expected.OverridesSprig {
+			t.Errorf("%s: expected %q %q %v, got %q %q %v", name,
+				expected.Signature, expected.Returns, expected.OverridesSprig,
+				fi.Signature, fi.Returns, fi.OverridesSprig)
+		}
+	}
+}
+
+// TestFunctionsOverridesSprig checks that OverridesSprig is set for exactly
+// the functions that sprig also has
+func TestFunctionsOverridesSprig(t *testing.T) {
+	sprigFuncs := sprig.GenericFuncMap()
+	for _, fi := range Functions() {
+		if _, ok := sprigFuncs[fi.Name]; fi.OverridesSprig != ok {
+			t.Errorf("%s: expected OverridesSprig to be %v", fi.Name, ok)
+		}
+	}
+
+	for _, fi := range Functions() {
+		if fi.Name == "trunc" && !fi.OverridesSprig {
+			t.Error("trunc: expected it to override sprig's trunc")
+		}
+	}
+}
+
+// TestFunctionsReturns checks that the functions described as returning a
+// float do, including when FloatFormat changes the type
+func TestFunctionsReturns(t *testing.T) {
+	for _, format := range []FloatFormat{FormatShortest, FormatPlain, FormatFixed, FormatTrimmed} {
+		funcMap := FuncMap(Options{FloatFormat: format, Decimals: 2})
+		for _, fi := range Functions() {
+			if fi.Returns != "float" {
+				continue
+			}
+			if k := reflect.TypeOf(funcMap[fi.Name]).Out(0).Kind(); k != reflect.Float64 {
+				t.Errorf("%s (format %d): described as a float, but returns a %s", fi.Name, format, k)
+			}
+		}
+	}
+}
+
+func TestFunctionsCustom(t *testing.T) {
+	m := NewMath(Options{})
+	m.RegisterUnary("sigmoid", func(x float64) float64 { return 1 / (1 + math.Exp(-x)) })
+	m.RegisterBinary("logn", func(b, x float64) float64 { return math.Log(x) / math.Log(b) })
+	m.Register("mean", func(xs ...float64) float64 { return 0 })
+	m.Describe(FuncInfo{Name: "mean", Signature: "mean values...", Description: "The mean."})
+	m.Describe(FuncInfo{
+		Name:        "logn",
+		Args:        []string{"base", "x"},
+		Description: "The logarithm of x in base.",
+		Examples:    []Example{{`{{ logn 2 8 }}`, "3"}},
+	})
+	m.RegisterUnary("sqrt", math.Sqrt)
+
+	byName := map[string]FuncInfo{}
+	for _, fi := range m.Functions() {
+		if _, ok := byName[fi.Name]; ok {
+			t.Errorf("%s is described twice", fi.Name)
+		}
+		byName[fi.Name] = fi
+	}
+
+	if fi := byName["sigmoid"]; fi.Signature != "sigmoid arg0" || fi.Returns != "float" {
+		t.Errorf("unexpected sigmoid info %#v", fi)
+	}
+	if fi := byName["logn"]; fi.Signature != "logn base x" || fi.Description == "" || len(fi.Examples) != 1 {
+		t.Errorf("unexpected logn info %#v", fi)
+	}
+	if fi := byName["mean"]; fi.Signature != "mean values..." || fi.Returns != "float" {
+		t.Errorf("expected the described signature to be kept, got %#v", fi)
+	}
+	if fi := byName["sqrt"]; fi.Description != "" {
+		t.Errorf("expected the registered sqrt to replace the built in one, got %#v", fi)
+	}
+	for _, fi := range Functions() {
+		if fi.Name == "sigmoid" {
+			t.Error("sigmoid should only be described by the Math it was registered with")
+		}
+	}
+}
+
+func TestFunctionsJSON(t *testing.T) {
+	b, err := FunctionsJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var infos []map[string]interface{}
+	if err := json.Unmarshal(b, &infos); err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != len(Functions()) {
+		t.Errorf("expected %d functions, got %d", len(Functions()), len(infos))
+	}
+
+	for _, fi := range infos {
+		if fi["name"] != "pi" {
+			continue
+		}
+		if args, ok := fi["args"].([]interface{}); !ok || len(args) != 0 {
+			t.Errorf("expected pi to have an empty list of args, got %v", fi["args"])
+		}
+		if fi["signature"] != "pi" || fi["returns"] != "float" || fi["overridesSprig"] != false {
+			t.Errorf("unexpected pi info %v", fi)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package sprigmath
+
+import (
+	"reflect"
+)
+
+// MissingPolicy selects what functions do with missing values: nil, nil
+// pointers, and the Null returned by other functions. Missing map keys are
+// passed to functions as nil by text/template.
+type MissingPolicy int
+
+const (
+	// MissingError makes functions fail with an error wrapping ErrMissing
+	MissingError MissingPolicy = iota
+
+	// MissingZero makes functions treat missing values as 0
+	MissingZero
+
+	// MissingNull makes functions return Null when any of their arguments
+	// is missing, which renders as nothing
+	MissingNull
+)
+
+// Null is the result of a function given a missing value when the policy is
+// MissingNull. It renders as an empty string, and is itself a missing value,
+// so it propagates through a pipeline.
+type Null struct{}
+
+func (Null) String() string {
+	return ""
+}
+
+// isMissing reports whether v is nil, a nil pointer or Null
+func isMissing(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	if _, ok := v.(Null); ok {
+		return true
+	}
+	val := reflect.ValueOf(v)
+	return val.Kind() == reflect.Ptr && val.IsNil()
+}
+
+// missingSafe are the functions that handle missing values themselves
+var missingSafe = map[string]bool{
+	"try":        true,
+	"isNumber":   true,
+	"isInt":      true,
+	"isFloat":    true,
+	"isFinite":   true,
+	"isNaN":      true,
+	"isInf":      true,
+	"isPositive": true,
+	"isEven":     true,
+	"isOdd":      true,
+	"inRange":    true,
+}
+
+var (
+	interfaceType = reflect.TypeOf((*interface{})(nil)).Elem()
+	nullValue     = reflect.ValueOf(Null{})
+)
+
+// missingValues wraps a function to implement MissingZero and MissingNull.
+// MissingError needs no wrapper, as the conversions already fail on missing
+// values. With MissingNull the first result becomes an interface{}, so that
+// it can be Null.
+func missingValues(fn interface{}, policy MissingPolicy) interface{} {
+	if policy == MissingError {
+		return fn
+	}
+
+	fv := reflect.ValueOf(fn)
+	ft := fv.Type()
+	if ft.NumIn() == 0 {
+		return fn
+	}
+
+	in := make([]reflect.Type, ft.NumIn())
+	for i := range in {
+		in[i] = ft.In(i)
+	}
+	out := make([]reflect.Type, ft.NumOut())
+	for i := range out {
+		out[i] = ft.Out(i)
+	}
+	if policy == MissingNull && len(out) > 0 {
+		out[0] = interfaceType
+	}
+
+	wt := reflect.FuncOf(in, out, ft.IsVariadic())
+	zero := reflect.New(interfaceType).Elem()
+	zero.Set(reflect.ValueOf(int64(0)))
+
+	return reflect.MakeFunc(wt, func(args []reflect.Value) []reflect.Value {
+		// the variadic arguments arrive as a slice in the last argument
+		values := append([]reflect.Value{}, args...)
+		if ft.IsVariadic() {
+			last := values[len(values)-1]
+			values = values[:len(values)-1]
+			for i := 0; i < last.Len(); i++ {
+				values = append(values, last.Index(i))
+			}
+		}
+
+		missing := false
+		for i, v := range values {
+			if v.Type() != interfaceType || !isMissing(v.Interface()) {
+				continue
+			}
+			missing = true
+			values[i] = zero
+		}
+
+		if missing && policy == MissingNull {
+			results := make([]reflect.Value, len(out))
+			for i := range results {
+				results[i] = reflect.Zero(out[i])
+			}
+			results[0] = nullValue.Convert(interfaceType)
+			return results
+		}
+
+		results := fv.Call(values)
+		if policy == MissingNull {
+			results[0] = results[0].Convert(interfaceType)
+		}
+		return results
+	}).Interface()
+}
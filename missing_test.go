@@ -0,0 +1,75 @@
+package sprigmath
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestMissing(t *testing.T) {
+	var nilPtr *int
+	vars := map[string]interface{}{
+		"x":   4,
+		"nil": nil,
+		"ptr": nilPtr,
+	}
+
+	for _, tc := range []struct {
+		policy   MissingPolicy
+		tpl      string
+		expected string
+	}{
+		{MissingZero, `{{ add .x .missing }}`, "4"},
+		{MissingZero, `{{ .nil | add1 }}`, "1"},
+		{MissingZero, `{{ sqrt .ptr }}`, "0"},
+		{MissingZero, `{{ max .x .nil 7 }}`, "7"},
+		{MissingZero, `{{ int64 .missing }}`, "0"},
+		{MissingZero, `{{ isNumber .nil }}`, "false"},
+		{MissingNull, `[{{ add .x .missing }}]`, "[]"},
+		{MissingNull, `[{{ .missing | sqrt | mul 2 | formatNumber "en" }}]`, "[]"},
+		{MissingNull, `[{{ max 1 2 .nil }}]`, "[]"},
+		{MissingNull, `[{{ add .x 1 }}]`, "[5]"},
+		{MissingNull, `[{{ sqrt .x }}]`, "[2]"},
+		{MissingNull, `[{{ isNumber .nil }}]`, "[false]"},
+		{MissingNull, `[{{ try "sqrt" .nil 3 }}]`, "[3]"},
+		{MissingNull, `[{{ try "add" .x 1 0 }}]`, "[5]"},
+		{MissingZero, `{{ try "log" .missing 1 }}`, "1"},
+		{MissingZero, `{{ try "sqrt" .x 0 }}`, "2"},
+	} {
+		out, err := runOpts(Options{Missing: tc.policy}, tc.tpl, vars)
+		if err != nil || out != tc.expected {
+			t.Errorf("%s (policy %d): expected %q, got %q %v", tc.tpl, tc.policy, tc.expected, out, err)
+		}
+	}
+
+	for tpl, errstr := range map[string]string{
+		`{{ add .x .missing }}`: "add[arg1]: missing value",
+		`{{ sqrt .nil }}`:       "sqrt[arg0]: missing value",
+		`{{ sqrt .ptr }}`:       "sqrt[arg0]: missing value",
+		`{{ int64 .missing }}`:  "missing value",
+	} {
+		_, err := runRaw(tpl, vars)
+		if !errors.Is(err, ErrMissing) {
+			t.Errorf("%s: expected ErrMissing, got %v", tpl, err)
+		} else if err = testError(errstr, err); err != nil {
+			t.Errorf("%s: %v", tpl, err)
+		}
+	}
+
+	// Null is missing too, so results propagate when used from Go
+	if _, err := toFloat64(Null{}); !errors.Is(err, ErrMissing) {
+		t.Errorf("expected ErrMissing for Null, got %v", err)
+	}
+}
+
+func TestMissingFormat(t *testing.T) {
+	opts := Options{Missing: MissingNull, FloatFormat: FormatFixed, Decimals: 2}
+	for tpl, expected := range map[string]string{
+		`[{{ sqrt .missing }}]`: "[]",
+		`[{{ sqrt 2 }}]`:        "[1.41]",
+	} {
+		if out, err := runOpts(opts, tpl, nil); err != nil || out != expected {
+			t.Errorf("%s: expected %q, got %q %v", tpl, expected, out, err)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package sprigmath
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// siPrefixes maps powers of 1000 to SI prefixes, from 10^-24 to 10^24. The
+// newer ronna and quetta prefixes are left out, as parseSI reads R as the
+// point of an RKM code, so eng output can always be parsed back.
+var siPrefixes = []string{"y", "z", "a", "f", "p", "n", "µ", "m", "", "k", "M", "G", "T", "P", "E", "Z", "Y"}
+
+var superscripts = strings.NewReplacer(
+	"-", "⁻", "0", "⁰", "1", "¹", "2", "²", "3", "³", "4", "⁴",
+	"5", "⁵", "6", "⁶", "7", "⁷", "8", "⁸", "9", "⁹",
+)
+
+// splitSci rounds v to digits significant digits, and returns the mantissa
+// and the power of ten it must be multiplied by. If eng is set, the exponent
+// is a multiple of three and the mantissa is between 1 and 1000.
+func splitSci(v float64, digits int, eng bool) (string, int) {
+	s := strconv.FormatFloat(v, 'e', digits-1, 64)
+	i := strings.IndexByte(s, 'e')
+	mant := s[:i]
+	exp, _ := strconv.Atoi(s[i+1:])
+
+	if !eng || exp%3 == 0 || v == 0 {
+		return mant, exp
+	}
+
+	// move the point right until the exponent is a multiple of three
+	shift := exp - int(math.Floor(float64(exp)/3))*3
+	sign := ""
+	if strings.HasPrefix(mant, "-") {
+		sign, mant = "-", mant[1:]
+	}
+	d := strings.Replace(mant, ".", "", 1)
+	if len(d) < shift+1 {
+		d += strings.Repeat("0", shift+1-len(d))
+	}
+	mant = d[:shift+1]
+	if len(d) > shift+1 {
+		mant += "." + d[shift+1:]
+	}
+	return sign + mant, exp - shift
+}
+
+// notation implements the sci and eng families of functions
+func (f *funcs) notation(name string, digits interface{}, v interface{}, eng bool, format func(mant string, exp int) string) (string, error) {
+	n, err := f.toInt(digits)
+	if err != nil {
+		return "", argError(name, 0, digits, err)
+	}
+	if n < 1 {
+		return "", argError(name, 0, digits, newNumError(ErrDomain, "at least one significant digit is needed"))
+	}
+
+	fv, err := f.toFloat64(v)
+	if err != nil {
+		return "", argError(name, 1, v, err)
+	}
+	if math.IsNaN(fv) || math.IsInf(fv, 0) {
+		return strconv.FormatFloat(fv, 'g', -1, 64), nil
+	}
+
+	return format(splitSci(fv, n, eng)), nil
+}
+
+func plainExp(mant string, exp int) string {
+	return mant + "e" + strconv.Itoa(exp)
+}
+
+func unicodeExp(mant string, exp int) string {
+	return mant + "×10" + superscripts.Replace(strconv.Itoa(exp))
+}
+
+// sci writes v in scientific notation with the given number of significant
+// digits, such as "4.70e-6"
+func (f *funcs) sci(digits interface{}, v interface{}) (string, error) {
+	return f.notation("sci", digits, v, false, plainExp)
+}
+
+// sciUnicode writes v in scientific notation with the given number of
+// significant digits, such as "4.70×10⁻⁶"
+func (f *funcs) sciUnicode(digits interface{}, v interface{}) (string, error) {
+	return f.notation("sciUnicode", digits, v, false, unicodeExp)
+}
+
+// eng writes v in engineering notation with an SI prefix and the given number
+// of significant digits, such as "4.70 µ". Values outside of the range of
+// the SI prefixes are written like engExp.
+func (f *funcs) eng(digits interface{}, v interface{}) (string, error) {
+	return f.notation("eng", digits, v, true, func(mant string, exp int) string {
+		i := exp/3 + len(siPrefixes)/2
+		if i < 0 || i >= len(siPrefixes) {
+			return plainExp(mant, exp)
+		}
+		if siPrefixes[i] == "" {
+			return mant
+		}
+		return mant + " " + siPrefixes[i]
+	})
+}
+
+// engExp writes v in engineering notation with the given number of
+// significant digits, such as "4.70e-6"
+func (f *funcs) engExp(digits interface{}, v interface{}) (string, error) {
+	return f.notation("engExp", digits, v, true, plainExp)
+}
+
+// engUnicode writes v in engineering notation with the given number of
+// significant digits, such as "4.70×10⁻⁶"
+func (f *funcs) engUnicode(digits interface{}, v interface{}) (string, error) {
+	return f.notation("engUnicode", digits, v, true, unicodeExp)
+}
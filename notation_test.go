@@ -0,0 +1,39 @@
+package sprigmath
+
+import (
+	"testing"
+)
+
+func TestNotation(t *testing.T) {
+	for tpl, expected := range map[string]string{
+		`{{ sci 3 4.7e-6 }}`:         "4.70e-6",
+		`{{ sci 1 123456 }}`:         "1e5",
+		`{{ sci 2 -0.000123 }}`:      "-1.2e-4",
+		`{{ sciUnicode 3 4.7e-6 }}`:  "4.70×10⁻⁶",
+		`{{ sciUnicode 2 12 }}`:      "1.2×10¹",
+		`{{ eng 2 4.7e-6 }}`:         "4.7 µ",
+		`{{ eng 3 47000 }}`:          "47.0 k",
+		`{{ eng 1 47000 }}`:          "50 k",
+		`{{ eng 3 -2200000 }}`:       "-2.20 M",
+		`{{ eng 2 470 }}`:            "470",
+		`{{ eng 4 0 }}`:              "0.000",
+		`{{ eng 2 1e33 }}`:           "1.0e33",
+		`{{ eng 3 1e27 }}`:           "1.00e27",
+		`{{ eng 3 1.5e-27 }}`:        "1.50e-27",
+		`{{ eng 3 1e24 }}`:           "1.00 Y",
+		`{{ eng 3 1e27 | parseSI }}`: "1000000000000000000000000000",
+		`{{ eng 3 2e24 | parseSI }}`: "2000000000000000000000000",
+		`{{ engExp 3 4.7e-5 }}`:      "47.0e-6",
+		`{{ engUnicode 2 0.00022 }}`: "220×10⁻⁶",
+		`{{ 4.7e-6 | sci 2 }}`:       "4.7e-6",
+		`{{ sci 3 (div 1 0.0) }}`:    "+Inf",
+	} {
+		if out, err := runRaw(tpl, nil); err != nil || out != expected {
+			t.Errorf("%s: expected %q, got %q %v", tpl, expected, out, err)
+		}
+	}
+
+	if err := runerr(`{{ eng 0 1 }}`, "eng[arg0]: at least one significant digit is needed"); err != nil {
+		t.Error(err)
+	}
+}
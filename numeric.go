@@ -1,25 +1,177 @@
 package sprigmath
 
 import (
+	"encoding"
 	"math"
+	"math/big"
 	"reflect"
+	"regexp"
 	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Float64er is implemented by values that can convert themselves to a
+// float64, such as json.Number. The conversion functions accept them.
+type Float64er interface {
+	Float64() (float64, error)
+}
+
+// Int64er is implemented by values that can convert themselves to an int64,
+// such as json.Number. The conversion functions accept them, and prefer
+// Int64 over Float64 when a value implements both.
+type Int64er interface {
+	Int64() (int64, error)
+}
+
+// fromInterface converts values that are not Go numeric kinds but know how to
+// convert themselves: math/big numbers, Int64er and Float64er. The result is
+// an int64 or a float64, and ok is false if v is none of these types.
+func fromInterface(v interface{}) (n interface{}, ok bool, err error) {
+	switch x := v.(type) {
+	case *big.Int:
+		if x == nil {
+			break
+		}
+		if x.IsInt64() {
+			return x.Int64(), true, nil
+		}
+		fv, _ := new(big.Float).SetInt(x).Float64()
+		return fv, true, nil
+	case *big.Float:
+		if x == nil {
+			break
+		}
+		fv, _ := x.Float64()
+		return fv, true, nil
+	case *big.Rat:
+		if x == nil {
+			break
+		}
+		if x.IsInt() && x.Num().IsInt64() {
+			return x.Num().Int64(), true, nil
+		}
+		fv, _ := x.Float64()
+		return fv, true, nil
+	case Int64er:
+		if iv, err := x.Int64(); err == nil {
+			return iv, true, nil
+		} else if fx, ok := v.(Float64er); ok {
+			fv, err := fx.Float64()
+			return fv, true, err
+		} else {
+			return nil, true, err
+		}
+	case Float64er:
+		fv, err := x.Float64()
+		return fv, true, err
+	}
+
+	return nil, false, nil
+}
 
-	"github.com/pkg/errors"
+// marshalText returns the text of values implementing encoding.TextMarshaler,
+// so that they can be parsed like a string
+func marshalText(v interface{}) (string, bool) {
+	if tm, ok := v.(encoding.TextMarshaler); ok {
+		if text, err := tm.MarshalText(); err == nil {
+			return string(text), true
+		}
+	}
+	return "", false
+}
+
+// Strings are parsed by all of the conversions with one grammar:
+//
+//	literal  = space* [sign] (decimal | hex | infinity | nan) space*
+//	decimal  = (digits ["." [digits]] | "." digits) [("e" | "E") [sign] digits]
+//	hex      = ("0x" | "0X") hexmantissa [("p" | "P") [sign] digits]
+//	infinity = "inf" | "infinity"      (in any case)
+//	nan      = "nan"                   (in any case, only if allowed)
+//
+// A literal is an integer when it has no point and its value is a whole
+// number in the int64 range, so "1e3" and "0x1F" are integers, while "1.0",
+// "1e-3" and "1e19" are floats. A hex mantissa with a point needs an exponent,
+// as in Go. Values too large for a float64 are not accepted, and values too
+// small are rounded to zero.
+var (
+	decimalLiteral = regexp.MustCompile(`^([0-9]+(\.[0-9]*)?|\.[0-9]+)([eE][+-]?[0-9]+)?$`)
+	hexLiteral     = regexp.MustCompile(`^0[xX]([0-9a-fA-F]+|([0-9a-fA-F]+\.?[0-9a-fA-F]*|\.[0-9a-fA-F]+)[pP][+-]?[0-9]+)$`)
 )
 
+// parseLiteral parses s using the literal grammar, returning an int64 or a
+// float64. NaN is only accepted when nan is true.
+func parseLiteral(s string, nan bool) (interface{}, bool) {
+	s = strings.TrimSpace(s)
+
+	body := s
+	if strings.HasPrefix(body, "+") || strings.HasPrefix(body, "-") {
+		body = body[1:]
+	}
+
+	switch strings.ToLower(body) {
+	case "inf", "infinity":
+		if s[0] == '-' {
+			return math.Inf(-1), true
+		}
+		return math.Inf(1), true
+	case "nan":
+		return math.NaN(), nan
+	}
+
+	if !decimalLiteral.MatchString(body) && !hexLiteral.MatchString(body) {
+		return nil, false
+	}
+
+	text := s
+	if hexLiteral.MatchString(body) && !strings.ContainsAny(body, "pP") {
+		// strconv needs an exponent for hex
+		text += "p0"
+	}
+	fv, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		// out of range
+		return nil, false
+	}
+
+	// check exactly whether a literal without a point is an int64, as a
+	// float64 cannot hold all of them
+	if !strings.Contains(body, ".") && math.Abs(fv) <= 1<<63 {
+		if r, ok := new(big.Rat).SetString(s); ok && r.IsInt() && r.Num().IsInt64() {
+			return r.Num().Int64(), true
+		}
+	}
+	return fv, true
+}
+
 //
 // Copied from sprig, BSD license
 //
 
 // toFloat64 converts 64-bit floats
 func toFloat64(v interface{}) (float64, error) {
+	if isMissing(v) {
+		return 0, errMissing
+	}
 	if str, ok := v.(string); ok {
-		iv, err := strconv.ParseFloat(str, 64)
+		n, ok := parseLiteral(str, false)
+		if !ok {
+			return 0, newNumError(ErrNotNumber, "cannot convert %v to float64", v)
+		}
+		if iv, ok := n.(int64); ok {
+			return float64(iv), nil
+		}
+		return n.(float64), nil
+	}
+
+	if n, ok, err := fromInterface(v); ok {
 		if err != nil {
-			return 0, errors.Errorf("cannot convert %v to float64", v)
+			return 0, newNumError(ErrNotNumber, "cannot convert %v to float64", v)
 		}
-		return iv, nil
+		if iv, ok := n.(int64); ok {
+			return float64(iv), nil
+		}
+		return n.(float64), nil
 	}
 
 	val := reflect.Indirect(reflect.ValueOf(v))
@@ -38,7 +190,10 @@ func toFloat64(v interface{}) (float64, error) {
 		}
 		return 0, nil
 	default:
-		return 0, errors.Errorf("cannot convert %v to float64", v)
+		if str, ok := marshalText(v); ok {
+			return toFloat64(str)
+		}
+		return 0, newNumError(ErrNotNumber, "cannot convert %v to float64", v)
 	}
 }
 
@@ -50,14 +205,31 @@ func toInt(v interface{}) (int, error) {
 
 // toInt64 converts integer types to 64-bit integers
 func toInt64(v interface{}) (int64, error) {
+	if isMissing(v) {
+		return 0, errMissing
+	}
 	if str, ok := v.(string); ok {
-		iv, err := strconv.ParseInt(str, 10, 64)
-		if err != nil {
-			return 0, errors.Errorf("cannot convert %v to int64", v)
+		n, _ := parseLiteral(str, false)
+		iv, ok := n.(int64)
+		if !ok {
+			return 0, newNumError(ErrNotNumber, "cannot convert %v to int64", v)
 		}
 		return iv, nil
 	}
 
+	if n, ok, err := fromInterface(v); ok {
+		if err != nil {
+			return 0, newNumError(ErrNotNumber, "cannot convert %v to int64", v)
+		}
+		if fv, ok := n.(float64); ok {
+			if !(fv >= math.MinInt64 && fv < math.MaxInt64) {
+				return 0, newNumError(ErrOverflow, "%v is too big", v)
+			}
+			return int64(fv), nil
+		}
+		return n.(int64), nil
+	}
+
 	val := reflect.Indirect(reflect.ValueOf(v))
 	switch val.Kind() {
 	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int:
@@ -69,7 +241,7 @@ func toInt64(v interface{}) (int64, error) {
 		if tv <= math.MaxInt64 {
 			return int64(tv), nil
 		}
-		return math.MaxInt64, errors.Errorf("%v is too big", tv)
+		return math.MaxInt64, newNumError(ErrOverflow, "%v is too big", tv)
 	case reflect.Float32, reflect.Float64:
 		return int64(val.Float()), nil
 	case reflect.Bool:
@@ -78,24 +250,30 @@ func toInt64(v interface{}) (int64, error) {
 		}
 		return 0, nil
 	default:
-		return 0, errors.Errorf("cannot convert %v to int64", v)
+		if str, ok := marshalText(v); ok {
+			return toInt64(str)
+		}
+		return 0, newNumError(ErrNotNumber, "cannot convert %v to int64", v)
 	}
 }
 
 // converts to either an int64 or a float64
 func toNumber(v interface{}) (interface{}, error) {
+	if isMissing(v) {
+		return nil, errMissing
+	}
 	if str, ok := v.(string); ok {
-		iv, err := strconv.ParseInt(str, 10, 64)
-		if err == nil {
-			return iv, nil
+		if n, ok := parseLiteral(str, false); ok {
+			return n, nil
 		}
+		return nil, newNumError(ErrNotNumber, "%v is not a float64 or int64", v)
+	}
 
-		fv, err := strconv.ParseFloat(str, 64)
-		if err == nil {
-			return fv, nil
+	if n, ok, err := fromInterface(v); ok {
+		if err != nil {
+			return nil, newNumError(ErrNotNumber, "%v is not a float64 or int64", v)
 		}
-
-		return nil, errors.Errorf("%v is not a float64 or int64", v)
+		return n, nil
 	}
 
 	val := reflect.Indirect(reflect.ValueOf(v))
@@ -109,15 +287,193 @@ func toNumber(v interface{}) (interface{}, error) {
 		if tv <= math.MaxInt64 {
 			return int64(tv), nil
 		}
-		return math.MaxInt64, errors.Errorf("%v is too big", tv)
+		return math.MaxInt64, newNumError(ErrOverflow, "%v is too big", tv)
 	case reflect.Float32, reflect.Float64:
 		return val.Float(), nil
 	case reflect.Bool:
 		if val.Bool() == true {
-			return 1, nil
+			return int64(1), nil
 		}
-		return 0, nil
+		return int64(0), nil
 	default:
-		return nil, errors.Errorf("cannot convert %v to float64 or int64", v)
+		if str, ok := marshalText(v); ok {
+			return toNumber(str)
+		}
+		return nil, newNumError(ErrNotNumber, "cannot convert %v to float64 or int64", v)
+	}
+}
+
+// integralFloat converts a float64 with no fractional part that is within the
+// range of an int64 to an int64. Other values are returned unchanged.
+func integralFloat(n interface{}) interface{} {
+	if fv, ok := n.(float64); ok && fv == math.Trunc(fv) && fv >= math.MinInt64 && fv < math.MaxInt64 {
+		return int64(fv)
+	}
+	return n
+}
+
+// toWholeNumber converts v to an exact integer, failing for values with a
+// fractional part rather than truncating them
+func toWholeNumber(v interface{}) (*big.Int, error) {
+	if isMissing(v) {
+		return nil, errMissing
+	}
+
+	// unsigned values may not fit in an int64, which toRat goes through
+	val := reflect.Indirect(reflect.ValueOf(v))
+	switch val.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return new(big.Int).SetUint64(val.Uint()), nil
+	}
+
+	r, err := toRat(v)
+	if err != nil {
+		return nil, err
+	}
+	if !r.IsInt() {
+		return nil, newNumError(ErrDomain, "%v is not a whole number", v)
+	}
+	return r.Num(), nil
+}
+
+const (
+	maxInt = 1<<(strconv.IntSize-1) - 1
+	minInt = -maxInt - 1
+)
+
+// toWholeNumber converts v like the package level toWholeNumber, reading
+// strings as toNumber does, with Locale, SIPrefixes and ParseNaN
+func (f *funcs) toWholeNumber(v interface{}) (*big.Int, error) {
+	lit, err := f.literal(v)
+	if err != nil {
+		return nil, err
+	}
+	if fv, ok := lit.(float64); ok && math.IsNaN(fv) {
+		return nil, newNumError(ErrDomain, "%v is not a whole number", v)
+	}
+	return toWholeNumber(lit)
+}
+
+// hasLetter reports whether s has a letter in it, such as an exponent
+func hasLetter(s string) bool {
+	return strings.IndexFunc(s, unicode.IsLetter) != -1
+}
+
+// toInt64 converts v like the package level toInt64, reading strings as
+// toNumber does
+func (f *funcs) toInt64(v interface{}) (int64, error) {
+	v, err := f.literal(v)
+	if err != nil {
+		return 0, err
+	}
+	return toInt64(v)
+}
+
+// toInt converts v like the package level toInt, reading strings as toNumber
+// does
+func (f *funcs) toInt(v interface{}) (int, error) {
+	v, err := f.literal(v)
+	if err != nil {
+		return 0, err
+	}
+	return toInt(v)
+}
+
+// toIntRange converts v to a whole number between min and max, failing
+// rather than truncating or wrapping around
+func (f *funcs) toIntRange(v interface{}, typ string, min int64, max uint64) (*big.Int, error) {
+	n, err := f.toWholeNumber(v)
+	if err != nil {
+		return nil, err
+	}
+	if n.Cmp(big.NewInt(min)) < 0 || n.Cmp(new(big.Int).SetUint64(max)) > 0 {
+		return nil, newNumError(ErrOverflow, "%v overflows %s", v, typ)
+	}
+	return n, nil
+}
+
+func (f *funcs) int(v interface{}) (int, error) {
+	if !f.Strict {
+		n, err := f.toInt(v)
+		if err != nil {
+			return 0, argError("int", 0, v, err)
+		}
+		return n, nil
+	}
+	n, err := f.toIntRange(v, "int", minInt, maxInt)
+	if err != nil {
+		return 0, argError("int", 0, v, err)
+	}
+	return int(n.Int64()), nil
+}
+
+func (f *funcs) int64(v interface{}) (int64, error) {
+	if !f.Strict {
+		n, err := f.toInt64(v)
+		if err != nil {
+			return 0, argError("int64", 0, v, err)
+		}
+		return n, nil
+	}
+	n, err := f.toIntRange(v, "int64", math.MinInt64, math.MaxInt64)
+	if err != nil {
+		return 0, argError("int64", 0, v, err)
+	}
+	return n.Int64(), nil
+}
+
+func (f *funcs) int8(v interface{}) (int8, error) {
+	n, err := f.toIntRange(v, "int8", math.MinInt8, math.MaxInt8)
+	if err != nil {
+		return 0, argError("int8", 0, v, err)
+	}
+	return int8(n.Int64()), nil
+}
+
+func (f *funcs) int16(v interface{}) (int16, error) {
+	n, err := f.toIntRange(v, "int16", math.MinInt16, math.MaxInt16)
+	if err != nil {
+		return 0, argError("int16", 0, v, err)
+	}
+	return int16(n.Int64()), nil
+}
+
+func (f *funcs) int32(v interface{}) (int32, error) {
+	n, err := f.toIntRange(v, "int32", math.MinInt32, math.MaxInt32)
+	if err != nil {
+		return 0, argError("int32", 0, v, err)
+	}
+	return int32(n.Int64()), nil
+}
+
+func (f *funcs) uint8(v interface{}) (uint8, error) {
+	n, err := f.toIntRange(v, "uint8", 0, math.MaxUint8)
+	if err != nil {
+		return 0, argError("uint8", 0, v, err)
+	}
+	return uint8(n.Uint64()), nil
+}
+
+func (f *funcs) uint16(v interface{}) (uint16, error) {
+	n, err := f.toIntRange(v, "uint16", 0, math.MaxUint16)
+	if err != nil {
+		return 0, argError("uint16", 0, v, err)
+	}
+	return uint16(n.Uint64()), nil
+}
+
+func (f *funcs) uint32(v interface{}) (uint32, error) {
+	n, err := f.toIntRange(v, "uint32", 0, math.MaxUint32)
+	if err != nil {
+		return 0, argError("uint32", 0, v, err)
+	}
+	return uint32(n.Uint64()), nil
+}
+
+func (f *funcs) uint64(v interface{}) (uint64, error) {
+	n, err := f.toIntRange(v, "uint64", 0, math.MaxUint64)
+	if err != nil {
+		return 0, argError("uint64", 0, v, err)
 	}
+	return n.Uint64(), nil
 }
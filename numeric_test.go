@@ -1,6 +1,10 @@
 package sprigmath
 
 import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"strconv"
 	"testing"
 
 	"github.com/pkg/errors"
@@ -221,3 +225,299 @@ func TestToNumber(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestIntegralFloats(t *testing.T) {
+	for in, expected := range map[float64]interface{}{
+		3:        int64(3),
+		-3:       int64(-3),
+		3.5:      3.5,
+		1e19:     1e19,
+		-1 << 63: int64(-1 << 63),
+		1 << 63:  float64(1 << 63),
+	} {
+		if v := integralFloat(in); v != expected {
+			t.Errorf("%v: expected %T %v, got %T %v", in, expected, expected, v, v)
+		}
+	}
+
+	opts := Options{IntegralFloats: true}
+	vars := map[string]interface{}{"replicas": 3.0, "scale": 1000.0}
+	if out, err := runOpts(opts, `{{ add .replicas 1 }} {{ mul .scale .scale }} {{ number .replicas | printf "%T" }}`, vars); err != nil || out != "4 1000000 int64" {
+		t.Errorf("Expected '4 1000000 int64', got %q %v", out, err)
+	}
+	if err := runtv(`{{ mul .scale .scale }}`, "1e+06", vars); err != nil {
+		t.Error(err)
+	}
+}
+
+type celsius float64
+
+func (c celsius) MarshalText() ([]byte, error) {
+	return []byte(strconv.FormatFloat(float64(c), 'f', -1, 64)), nil
+}
+
+type textNumber struct{ text string }
+
+func (n textNumber) MarshalText() ([]byte, error) {
+	return []byte(n.text), nil
+}
+
+type meters struct{ v float64 }
+
+func (m meters) Float64() (float64, error) {
+	return m.v, nil
+}
+
+func TestConversionInterfaces(t *testing.T) {
+	for _, tc := range []struct {
+		in     interface{}
+		number interface{}
+		f      float64
+		i      int64
+	}{
+		{json.Number("42"), int64(42), 42, 42},
+		{json.Number("4.5"), 4.5, 4.5, 4},
+		{big.NewInt(-7), int64(-7), -7, -7},
+		{big.NewFloat(2.5), 2.5, 2.5, 2},
+		{big.NewRat(3, 4), 0.75, 0.75, 0},
+		{big.NewRat(8, 2), int64(4), 4, 4},
+		{meters{1.5}, 1.5, 1.5, 1},
+		{textNumber{"12"}, int64(12), 12, 12},
+		{celsius(21.5), 21.5, 21.5, 21},
+	} {
+		if n, err := toNumber(tc.in); err != nil || n != tc.number {
+			t.Errorf("toNumber(%v): expected %T %v, got %T %v (%v)", tc.in, tc.number, tc.number, n, n, err)
+		}
+		if err := testFloat(tc.f, tc.in, ""); err != nil {
+			t.Errorf("toFloat64(%v): %v", tc.in, err)
+		}
+		if err := testInt64(tc.i, tc.in, ""); err != nil {
+			t.Errorf("toInt64(%v): %v", tc.in, err)
+		}
+	}
+
+	huge, _ := new(big.Int).SetString("100000000000000000000", 10)
+	if err := testInt64(0, huge, "100000000000000000000 is too big"); err != nil {
+		t.Error(err)
+	}
+	if err := testFloat(1e20, huge, ""); err != nil {
+		t.Error(err)
+	}
+	if err := testFloat(0, json.Number("bob"), "cannot convert bob to float64"); err != nil {
+		t.Error(err)
+	}
+	if err := testFloat(0, textNumber{"bob"}, "cannot convert bob to float64"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNarrowingConversions(t *testing.T) {
+	vars := map[string]interface{}{
+		"three":   3.0,
+		"frac":    3.9,
+		"maxU64":  uint64(18446744073709551615),
+		"bigU64":  "18446744073709551615",
+		"json":    json.Number("127"),
+		"big":     new(big.Int).Lsh(big.NewInt(1), 64),
+		"decimal": "1.50",
+	}
+
+	for tpl, expected := range map[string]string{
+		`{{ int8 127 }}`:             "127",
+		`{{ int8 -128 }}`:            "-128",
+		`{{ int8 .json }}`:           "127",
+		`{{ int16 .three }}`:         "3",
+		`{{ int32 "2147483647" }}`:   "2147483647",
+		`{{ uint8 255 }}`:            "255",
+		`{{ uint16 "65535" }}`:       "65535",
+		`{{ uint32 4294967295 }}`:    "4294967295",
+		`{{ uint64 .maxU64 }}`:       "18446744073709551615",
+		`{{ uint64 .bigU64 }}`:       "18446744073709551615",
+		`{{ uint8 true }}`:           "1",
+		`{{ int 3.9 }}`:              "3",
+		`{{ int64 .frac }}`:          "3",
+		`{{ .frac | trunc | int8 }}`: "3",
+	} {
+		if err := runtv(tpl, expected, vars); err != nil {
+			t.Error(err)
+		}
+	}
+
+	for tpl, errstr := range map[string]string{
+		`{{ int8 128 }}`:       "int8[arg0]: 128 overflows int8",
+		`{{ int16 -32769 }}`:   "int16[arg0]: -32769 overflows int16",
+		`{{ uint8 -1 }}`:       "uint8[arg0]: -1 overflows uint8",
+		`{{ uint32 .big }}`:    "uint32[arg0]: 18446744073709551616 overflows uint32",
+		`{{ uint64 .big }}`:    "uint64[arg0]: 18446744073709551616 overflows uint64",
+		`{{ int32 .frac }}`:    "int32[arg0]: 3.9 is not a whole number",
+		`{{ uint8 .decimal }}`: "uint8[arg0]: 1.50 is not a whole number",
+		`{{ int8 (inf 1) }}`:   "int8[arg0]: +Inf has no exact value",
+		`{{ uint16 "x" }}`:     "uint16[arg0]: x is not a float64 or int64",
+		`{{ int8 .missing }}`:  "int8[arg0]: missing value",
+		`{{ int "x" }}`:        "int[arg0]: cannot convert x to int64",
+		`{{ int64 "x" }}`:      "int64[arg0]: cannot convert x to int64",
+	} {
+		_, err := runRaw(tpl, vars)
+		if err = testError(errstr, err); err != nil {
+			t.Errorf("%s: %v", tpl, err)
+		}
+	}
+
+	// the options apply as they do to the other functions
+	for _, tc := range []struct {
+		opts     Options
+		tpl      string
+		expected string
+	}{
+		{Options{SIPrefixes: true}, `{{ uint16 "4k7" }}`, "4700"},
+		{Options{Locale: "de-DE"}, `{{ int32 "1.234" }}`, "1234"},
+		{Options{Locale: "de-DE", Strict: true}, `{{ int64 "-1.000.000" }}`, "-1000000"},
+	} {
+		if out, err := runOpts(tc.opts, tc.tpl, nil); err != nil || out != tc.expected {
+			t.Errorf("%s: expected %q, got %q %v", tc.tpl, tc.expected, out, err)
+		}
+	}
+	for _, tc := range []struct {
+		opts   Options
+		tpl    string
+		errstr string
+	}{
+		{Options{SIPrefixes: true}, `{{ int8 "4k" }}`, "int8[arg0]: 4k overflows int8"},
+		{Options{Locale: "de-DE"}, `{{ uint8 "1,5" }}`, "uint8[arg0]: 1.5 is not a whole number"},
+		{Options{ParseNaN: true}, `{{ int8 "NaN" }}`, "int8[arg0]: NaN is not a whole number"},
+	} {
+		_, err := runOpts(tc.opts, tc.tpl, nil)
+		if err = testError(tc.errstr, err); err != nil {
+			t.Errorf("%s: %v", tc.tpl, err)
+		}
+	}
+}
+
+func TestStrictIntConversions(t *testing.T) {
+	opts := Options{Strict: true}
+	vars := map[string]interface{}{"frac": 3.9, "huge": 1e19}
+
+	for tpl, expected := range map[string]string{
+		`{{ int 3 }}`:                       "3",
+		`{{ int64 "9223372036854775807" }}`: "9223372036854775807",
+		`{{ int64 4.0 }}`:                   "4",
+	} {
+		if out, err := runOpts(opts, tpl, vars); err != nil || out != expected {
+			t.Errorf("%s: expected %q, got %q %v", tpl, expected, out, err)
+		}
+	}
+
+	for tpl, errstr := range map[string]string{
+		`{{ int .frac }}`:   "int[arg0]: 3.9 is not a whole number",
+		`{{ int64 .frac }}`: "int64[arg0]: 3.9 is not a whole number",
+		`{{ int64 .huge }}`: "int64[arg0]: 1e+19 overflows int64",
+	} {
+		_, err := runOpts(opts, tpl, vars)
+		if err = testError(errstr, err); err != nil {
+			t.Errorf("%s: %v", tpl, err)
+		}
+	}
+
+	_, err := runOpts(opts, `{{ int64 .huge }}`, vars)
+	if !errors.Is(err, ErrOverflow) {
+		t.Errorf("expected ErrOverflow, got %v", err)
+	}
+}
+
+func TestLiteralGrammar(t *testing.T) {
+	for s, expected := range map[string]interface{}{
+		"42":                   int64(42),
+		"  -42\n":              int64(-42),
+		"+7":                   int64(7),
+		"1e3":                  int64(1000),
+		"1E+3":                 int64(1000),
+		"-2e2":                 int64(-200),
+		"15e-1":                1.5,
+		"10e-1":                int64(1),
+		"1e-3":                 0.001,
+		"1e19":                 1e19,
+		"1.0":                  1.0,
+		"1.":                   1.0,
+		".5":                   0.5,
+		"9223372036854775807":  int64(math.MaxInt64),
+		"-9223372036854775808": int64(math.MinInt64),
+		"9223372036854775808":  9223372036854775808.0,
+		"0x1F":                 int64(31),
+		"-0X10":                int64(-16),
+		"0x1p4":                int64(16),
+		"0x1.8p1":              3.0,
+		"0x.8p0":               0.5,
+		"inf":                  math.Inf(1),
+		"+Inf":                 math.Inf(1),
+		"-Infinity":            math.Inf(-1),
+		" INF ":                math.Inf(1),
+		"1e-400":               0.0,
+	} {
+		n, err := toNumber(s)
+		if err != nil || n != expected {
+			t.Errorf("toNumber(%q): expected %T %v, got %T %v %v", s, expected, expected, n, n, err)
+		}
+	}
+
+	for _, s := range []string{
+		"", " ", "+", "-", ".", "e3", "1e", "1e+", "1_000", "1,000", "0x", "0x1.8",
+		"0xg", "1.2.3", "--1", "+-1", "infinit", "nan", "NaN", "-nan", "1e400",
+		"0b101", "0o17", "1/2", "½",
+	} {
+		if n, err := toNumber(s); err == nil {
+			t.Errorf("toNumber(%q): expected an error, got %v", s, n)
+		}
+	}
+
+	for s, expected := range map[string]int64{
+		"1e3":   1000,
+		" 0x10": 16,
+		"-5":    -5,
+	} {
+		if iv, err := toInt64(s); err != nil || iv != expected {
+			t.Errorf("toInt64(%q): expected %v, got %v %v", s, expected, iv, err)
+		}
+	}
+	for _, s := range []string{"1.5", "1e-3", "inf", "1e19", "NaN"} {
+		if iv, err := toInt64(s); err == nil {
+			t.Errorf("toInt64(%q): expected an error, got %v", s, iv)
+		}
+	}
+
+	for s, expected := range map[string]float64{
+		"1e3":     1000,
+		"0x1.8p1": 3,
+		"-inf":    math.Inf(-1),
+		" 2.5 ":   2.5,
+	} {
+		if fv, err := toFloat64(s); err != nil || fv != expected {
+			t.Errorf("toFloat64(%q): expected %v, got %v %v", s, expected, fv, err)
+		}
+	}
+	if fv, err := toFloat64("NaN"); err == nil {
+		t.Errorf("toFloat64(\"NaN\"): expected an error, got %v", fv)
+	}
+}
+
+func TestParseNaN(t *testing.T) {
+	opts := Options{ParseNaN: true}
+	for tpl, expected := range map[string]string{
+		`{{ number "NaN" }}`:     "NaN",
+		`{{ float64 " -nan " }}`: "NaN",
+		`{{ sqrt "nan" }}`:       "NaN",
+		`{{ add 1 "NaN" }}`:      "NaN",
+		`{{ isNaN "NaN" }}`:      "true",
+		`{{ number "inf" }}`:     "+Inf",
+	} {
+		if out, err := runOpts(opts, tpl, nil); err != nil || out != expected {
+			t.Errorf("%s: expected %q, got %q %v", tpl, expected, out, err)
+		}
+	}
+
+	if err := runerr(`{{ sqrt "NaN" }}`, "sqrt[arg0]: cannot convert NaN to float64"); err != nil {
+		t.Error(err)
+	}
+	if err := runt(`{{ isNaN "NaN" }}`, "false"); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,97 @@
+package sprigmath
+
+import (
+	"math"
+	"math/big"
+	"strings"
+)
+
+// percentRat converts a percentage such as "15%", "0.5 %" or 15 to the exact
+// fraction it stands for. A number without a % sign is a percentage too, so
+// 0.15 stands for 0.0015 rather than 0.15.
+func (f *funcs) percentRat(v interface{}) (*big.Rat, error) {
+	if str, ok := v.(string); ok {
+		v = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(str), "%"))
+	}
+
+	r, err := f.toRat(v)
+	if err != nil {
+		return nil, err
+	}
+	return r.Quo(r, big.NewRat(100, 1)), nil
+}
+
+// percent converts a percentage such as "15%" or 15 to a fraction, 0.15
+func (f *funcs) percent(v interface{}) (float64, error) {
+	r, err := f.percentRat(v)
+	if err != nil {
+		return 0, argError("percent", 0, v, err)
+	}
+	fv, _ := r.Float64()
+	return fv, nil
+}
+
+// formatPercent formats a fraction as a percentage with the given number of
+// decimals, so 0.1234 is "12.3%" with one decimal
+func (f *funcs) formatPercent(decimals interface{}, v interface{}) (string, error) {
+	d, err := f.toInt(decimals)
+	if err != nil {
+		return "", argError("formatPercent", 0, decimals, err)
+	}
+	if d < 0 {
+		return "", argError("formatPercent", 0, decimals, newNumError(ErrDomain, "decimals must not be negative"))
+	}
+
+	r, err := f.toRat(v)
+	if err != nil {
+		return "", argError("formatPercent", 1, v, err)
+	}
+	return r.Mul(r, big.NewRat(100, 1)).FloatString(d) + "%", nil
+}
+
+// percentOf returns pct percent of v, where pct is a percentage such as
+// "15%" or 15
+func (f *funcs) percentOf(pct interface{}, v interface{}) (float64, error) {
+	r, err := f.percentRat(pct)
+	if err != nil {
+		return 0, argError("percentOf", 0, pct, err)
+	}
+
+	vf, err := f.toFloat64(v)
+	if err != nil {
+		return 0, argError("percentOf", 1, v, err)
+	}
+
+	pf, _ := r.Float64()
+	return f.checkFloat("percentOf", pf*vf, ErrOverflow, pf, vf)
+}
+
+// percentChange returns the change from old to new as a percentage of the
+// size of old, so that a rise from -100 to -50 is +50%
+func (f *funcs) percentChange(old interface{}, new interface{}) (float64, error) {
+	of, err := f.toFloat64(old)
+	if err != nil {
+		return 0, argError("percentChange", 0, old, err)
+	}
+
+	nf, err := f.toFloat64(new)
+	if err != nil {
+		return 0, argError("percentChange", 1, new, err)
+	}
+
+	// the change is relative to old, so a zero old value is the divisor
+	if f.Strict && of == 0 && !math.IsNaN(nf) && !math.IsInf(nf, 0) {
+		return 0, argError("percentChange", 0, old, ErrDivideByZero)
+	}
+
+	q, err := f.quo("percentChange", nf-of, math.Abs(of))
+	if err != nil {
+		return 0, err
+	}
+	return f.checkFloat("percentChange", q*100, ErrOverflow, q)
+}
+
+// ratio returns a divided by b, with the same division by zero policy as div
+func (f *funcs) ratio(a interface{}, b interface{}) (float64, error) {
+	return f.quo("ratio", a, b)
+}
@@ -0,0 +1,49 @@
+package sprigmath
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestPercent(t *testing.T) {
+	for tpl, expected := range map[string]string{
+		`{{ percent "15%" }}`:                     "0.15",
+		`{{ percent " 0.5 %" }}`:                  "0.005",
+		`{{ percent 15 }}`:                        "0.15",
+		`{{ percent 0.15 }}`:                      "0.0015",
+		`{{ percent "0.15" }}`:                    "0.0015",
+		`{{ formatPercent 1 0.1234 }}`:            "12.3%",
+		`{{ 0.5 | formatPercent 0 }}`:             "50%",
+		`{{ percent "15%" | formatPercent 2 }}`:   "15.00%",
+		`{{ 200 | percentOf "15%" }}`:             "30",
+		`{{ percentOf 50 3 }}`:                    "1.5",
+		`{{ percentChange 80 100 }}`:              "25",
+		`{{ percentChange 100 80 }}`:              "-20",
+		`{{ percentChange -100 -50 }}`:            "50",
+		`{{ percentChange -50 -100 }}`:            "-100",
+		`{{ ratio 3 4 }}`:                         "0.75",
+		`{{ ratio 1 0 }} {{ percentChange 0 1 }}`: "+Inf +Inf",
+	} {
+		if out, err := runRaw(tpl, nil); err != nil || out != expected {
+			t.Errorf("%s: expected %q, got %q %v", tpl, expected, out, err)
+		}
+	}
+
+	strict := Options{Strict: true}
+	for _, tpl := range []string{`{{ ratio 1 0 }}`, `{{ percentChange 0 1 }}`} {
+		if _, err := runOpts(strict, tpl, nil); !errors.Is(err, ErrDivideByZero) {
+			t.Errorf("%s: expected ErrDivideByZero, got %v", tpl, err)
+		}
+	}
+
+	var argErr *ArgError
+	_, err := runOpts(strict, `{{ percentChange "0" 5 }}`, nil)
+	if !errors.As(err, &argErr) || argErr.Func != "percentChange" || argErr.Index != 0 || argErr.Value != "0" {
+		t.Errorf("expected an ArgError for arg 0, got %#v", argErr)
+	}
+
+	if err := runerr(`{{ percent "x%" }}`, "percent[arg0]: x is not a float64 or int64"); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,85 @@
+package sprigmath
+
+import (
+	"math"
+)
+
+// The predicates return false rather than an error for values that are not
+// numbers, so that templates can validate input before converting it.
+
+// isNumber reports whether v can be converted to a number
+func (f *funcs) isNumber(v interface{}) bool {
+	_, err := f.toNumber(v)
+	return err == nil
+}
+
+// intValue converts v to an int64 if it is a whole number that fits, which
+// includes floats such as 3.0 that were decoded from JSON or YAML
+func (f *funcs) intValue(v interface{}) (int64, bool) {
+	iv, fv, isFloat, err := f.numberArg("", 0, v)
+	if err != nil {
+		return 0, false
+	}
+	if !isFloat {
+		return iv, true
+	}
+	if fv != math.Trunc(fv) || !(fv >= math.MinInt64 && fv < math.MaxInt64) {
+		return 0, false
+	}
+	return int64(fv), true
+}
+
+// isInt reports whether v is a whole number in the int64 range
+func (f *funcs) isInt(v interface{}) bool {
+	_, ok := f.intValue(v)
+	return ok
+}
+
+// isFloat reports whether v converts to a float64 rather than an int64, such
+// as 2.5 or "1.0"
+func (f *funcs) isFloat(v interface{}) bool {
+	n, err := f.toNumber(v)
+	if err != nil {
+		return false
+	}
+	_, ok := n.(float64)
+	return ok
+}
+
+func (f *funcs) isFinite(v interface{}) bool {
+	_, fv, _, err := f.numberArg("", 0, v)
+	return err == nil && !math.IsNaN(fv) && !math.IsInf(fv, 0)
+}
+
+func (f *funcs) isNaN(v interface{}) bool {
+	_, fv, _, err := f.numberArg("", 0, v)
+	return err == nil && math.IsNaN(fv)
+}
+
+func (f *funcs) isInf(v interface{}) bool {
+	_, fv, _, err := f.numberArg("", 0, v)
+	return err == nil && math.IsInf(fv, 0)
+}
+
+// isPositive reports whether v is greater than zero
+func (f *funcs) isPositive(v interface{}) bool {
+	_, fv, _, err := f.numberArg("", 0, v)
+	return err == nil && fv > 0
+}
+
+func (f *funcs) isEven(v interface{}) bool {
+	iv, ok := f.intValue(v)
+	return ok && iv%2 == 0
+}
+
+func (f *funcs) isOdd(v interface{}) bool {
+	iv, ok := f.intValue(v)
+	return ok && iv%2 != 0
+}
+
+// inRange reports whether lo <= x <= hi, like between, but is false when any
+// of them is not a number
+func (f *funcs) inRange(lo interface{}, hi interface{}, x interface{}) bool {
+	ok, err := f.between(lo, hi, x)
+	return ok && err == nil
+}
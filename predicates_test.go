@@ -0,0 +1,62 @@
+package sprigmath
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPredicates(t *testing.T) {
+	vars := map[string]interface{}{
+		"three": 3.0,
+		"half":  2.5,
+		"nan":   math.NaN(),
+		"inf":   math.Inf(-1),
+		"huge":  1e300,
+		"nil":   nil,
+		"list":  []int{1},
+	}
+
+	for tpl, expected := range map[string]string{
+		`{{ isNumber 1 }}`:         "true",
+		`{{ isNumber "1.5" }}`:     "true",
+		`{{ isNumber .half }}`:     "true",
+		`{{ isNumber "x" }}`:       "false",
+		`{{ isNumber .nil }}`:      "false",
+		`{{ isNumber .list }}`:     "false",
+		`{{ isInt 3 }}`:            "true",
+		`{{ isInt .three }}`:       "true",
+		`{{ isInt .half }}`:        "false",
+		`{{ isInt .huge }}`:        "false",
+		`{{ isInt "x" }}`:          "false",
+		`{{ isFloat .three }}`:     "true",
+		`{{ isFloat "1.0" }}`:      "true",
+		`{{ isFloat 1 }}`:          "false",
+		`{{ isFloat "x" }}`:        "false",
+		`{{ isFinite .huge }}`:     "true",
+		`{{ isFinite .inf }}`:      "false",
+		`{{ isFinite .nan }}`:      "false",
+		`{{ isFinite "x" }}`:       "false",
+		`{{ isNaN .nan }}`:         "true",
+		`{{ isNaN 1 }}`:            "false",
+		`{{ isNaN "x" }}`:          "false",
+		`{{ isInf .inf }}`:         "true",
+		`{{ isInf .huge }}`:        "false",
+		`{{ isPositive 1 }}`:       "true",
+		`{{ isPositive 0 }}`:       "false",
+		`{{ isPositive .nan }}`:    "false",
+		`{{ isPositive "x" }}`:     "false",
+		`{{ isEven 4 }}`:           "true",
+		`{{ isEven .three }}`:      "false",
+		`{{ isEven .half }}`:       "false",
+		`{{ isOdd .three }}`:       "true",
+		`{{ isOdd -3 }}`:           "true",
+		`{{ isOdd "x" }}`:          "false",
+		`{{ 5 | inRange 1 10 }}`:   "true",
+		`{{ 11 | inRange 1 10 }}`:  "false",
+		`{{ "x" | inRange 1 10 }}`: "false",
+	} {
+		if err := runtv(tpl, expected, vars); err != nil {
+			t.Error(err)
+		}
+	}
+}
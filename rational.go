@@ -0,0 +1,161 @@
+package sprigmath
+
+import (
+	"math/big"
+	"strings"
+)
+
+// Rational is an exact fraction. When any argument to add, sub, mul or div is
+// a Rational or a *big.Rat, they calculate exactly and return a Rational.
+// It renders as "3/4", or as "3" when it is a whole number.
+type Rational struct {
+	rat *big.Rat
+}
+
+// NewRational returns a Rational with the value of r
+func NewRational(r *big.Rat) Rational {
+	return Rational{new(big.Rat).Set(r)}
+}
+
+func (q Rational) value() *big.Rat {
+	if q.rat == nil {
+		return new(big.Rat)
+	}
+	return q.rat
+}
+
+// Rat returns the exact value of q
+func (q Rational) Rat() *big.Rat {
+	return new(big.Rat).Set(q.value())
+}
+
+// Float64 implements Float64er
+func (q Rational) Float64() (float64, error) {
+	fv, _ := q.value().Float64()
+	return fv, nil
+}
+
+func (q Rational) String() string {
+	return q.value().RatString()
+}
+
+// MarshalText renders q as a fraction
+func (q Rational) MarshalText() ([]byte, error) {
+	return []byte(q.String()), nil
+}
+
+// hasRational reports whether any of args should be calculated exactly
+func hasRational(args []interface{}) bool {
+	for _, arg := range args {
+		switch arg.(type) {
+		case Rational, *big.Rat:
+			return true
+		}
+	}
+	return false
+}
+
+// ratFold combines args from left to right with op, exactly
+func (f *funcs) ratFold(name string, args []interface{}, op func(z, x, y *big.Rat) *big.Rat) (Rational, error) {
+	var acc *big.Rat
+	for i, arg := range args {
+		r, err := f.toRat(arg)
+		if err != nil {
+			return Rational{}, argError(name, i, arg, err)
+		}
+		if acc == nil {
+			acc = r
+			continue
+		}
+		op(acc, acc, r)
+	}
+	return Rational{acc}, nil
+}
+
+// limitDenominator returns the closest fraction to r with a denominator of at
+// most max, found by walking the continued fraction of r
+func limitDenominator(r *big.Rat, max *big.Int) *big.Rat {
+	if r.Denom().Cmp(max) <= 0 {
+		return new(big.Rat).Set(r)
+	}
+
+	neg := r.Sign() < 0
+	n := new(big.Int).Abs(r.Num())
+	d := new(big.Int).Set(r.Denom())
+
+	p0, q0, p1, q1 := big.NewInt(0), big.NewInt(1), big.NewInt(1), big.NewInt(0)
+	for d.Sign() != 0 {
+		a, rem := new(big.Int).QuoRem(n, d, new(big.Int))
+		q2 := new(big.Int).Add(q0, new(big.Int).Mul(a, q1))
+		if q2.Cmp(max) > 0 {
+			break
+		}
+		p0, q0, p1, q1 = p1, q1, new(big.Int).Add(p0, new(big.Int).Mul(a, p1)), q2
+		n, d = d, rem
+	}
+
+	// the best approximation is either the last convergent, or the largest
+	// semiconvergent that still fits
+	k := new(big.Int).Quo(new(big.Int).Sub(max, q0), q1)
+	semi := new(big.Rat).SetFrac(
+		new(big.Int).Add(p0, new(big.Int).Mul(k, p1)),
+		new(big.Int).Add(q0, new(big.Int).Mul(k, q1)),
+	)
+	conv := new(big.Rat).SetFrac(p1, q1)
+
+	abs := new(big.Rat).Abs(r)
+	best := conv
+	if new(big.Rat).Abs(new(big.Rat).Sub(semi, abs)).Cmp(new(big.Rat).Abs(new(big.Rat).Sub(conv, abs))) < 0 {
+		best = semi
+	}
+	if neg {
+		best.Neg(best)
+	}
+	return best
+}
+
+// toFraction returns the closest fraction to v whose denominator is at most
+// maxDenominator, so `toFraction 1000 3.14159` is 355/113
+func (f *funcs) toFraction(maxDenominator interface{}, v interface{}) (Rational, error) {
+	max, err := f.toInt64(maxDenominator)
+	if err != nil {
+		return Rational{}, argError("toFraction", 0, maxDenominator, err)
+	}
+	if max < 1 {
+		return Rational{}, argError("toFraction", 0, maxDenominator, newNumError(ErrDomain, "maximum denominator must be at least 1"))
+	}
+
+	r, err := f.toRat(v)
+	if err != nil {
+		return Rational{}, argError("toFraction", 1, v, err)
+	}
+	return Rational{limitDenominator(r, big.NewInt(max))}, nil
+}
+
+// formatFraction writes v as a fraction such as "7/4", or as a whole number
+func (f *funcs) formatFraction(v interface{}) (string, error) {
+	r, err := f.toRat(v)
+	if err != nil {
+		return "", argError("formatFraction", 0, v, err)
+	}
+	return r.RatString(), nil
+}
+
+// formatMixed writes v as a mixed fraction such as "1 3/4"
+func (f *funcs) formatMixed(v interface{}) (string, error) {
+	r, err := f.toRat(v)
+	if err != nil {
+		return "", argError("formatMixed", 0, v, err)
+	}
+
+	whole, rem := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+	if whole.Sign() == 0 || rem.Sign() == 0 {
+		return r.RatString(), nil
+	}
+
+	var b strings.Builder
+	b.WriteString(whole.String())
+	b.WriteByte(' ')
+	b.WriteString(new(big.Rat).SetFrac(rem.Abs(rem), r.Denom()).RatString())
+	return b.String(), nil
+}
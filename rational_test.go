@@ -0,0 +1,58 @@
+package sprigmath
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestLimitDenominator(t *testing.T) {
+	for _, tc := range []struct {
+		r        *big.Rat
+		max      int64
+		expected string
+	}{
+		{big.NewRat(3141592653589793, 1000000000000000), 1000, "355/113"},
+		{big.NewRat(3141592653589793, 1000000000000000), 100, "311/99"},
+		{big.NewRat(3, 4), 8, "3/4"},
+		{big.NewRat(333, 1000), 10, "1/3"},
+		{big.NewRat(-333, 1000), 10, "-1/3"},
+		{big.NewRat(9, 10), 1, "1"},
+	} {
+		if r := limitDenominator(tc.r, big.NewInt(tc.max)); r.RatString() != tc.expected {
+			t.Errorf("limitDenominator(%v, %d): expected %s, got %s", tc.r, tc.max, tc.expected, r.RatString())
+		}
+	}
+}
+
+func TestRational(t *testing.T) {
+	vars := map[string]interface{}{"half": big.NewRat(1, 2)}
+
+	for tpl, expected := range map[string]string{
+		`{{ toFraction 100 0.75 }}`:                         "3/4",
+		`{{ toFraction 1000 3.14159265 }}`:                  "355/113",
+		`{{ add (toFraction 10 0.25) 0.5 }}`:                "3/4",
+		`{{ sub .half 1 }}`:                                 "-1/2",
+		`{{ mul .half .half 2 }}`:                           "1/2",
+		`{{ div 1 (toFraction 10 0.75) }}`:                  "4/3",
+		`{{ div .half 0.25 }}`:                              "2",
+		`{{ div 1 3 | printf "%T" }}`:                       "float64",
+		`{{ formatFraction 1.75 }}`:                         "7/4",
+		`{{ formatMixed 1.75 }} {{ formatMixed -1.75 }}`:    "1 3/4 -1 3/4",
+		`{{ formatMixed 0.5 }} {{ formatMixed 3 }}`:         "1/2 3",
+		`{{ div (toFraction 100 0.75) 3 | formatMixed }}`:   "1/4",
+		`{{ toFraction 10 0.75 | mul 4 | add 0.5 | sqrt }}`: "1.8708286933869707",
+	} {
+		if out, err := runRaw(tpl, vars); err != nil || out != expected {
+			t.Errorf("%s: expected %q, got %q %v", tpl, expected, out, err)
+		}
+	}
+
+	if _, err := runRaw(`{{ div .half 0 }}`, vars); !errors.Is(err, ErrDivideByZero) {
+		t.Errorf("Expected ErrDivideByZero, got %v", err)
+	}
+	if err := runerr(`{{ toFraction 0 1.5 }}`, "toFraction[arg0]: maximum denominator must be at least 1"); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,112 @@
+package sprigmath
+
+import (
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+// siPowers maps the SI prefixes accepted by parseSI to powers of ten. R is
+// the unit marker of RKM codes such as "4R7", and u and K are common ways of
+// writing µ and k.
+var siPowers = map[string]int{
+	"y": -24, "z": -21, "a": -18, "f": -15, "p": -12, "n": -9,
+	"µ": -6, "μ": -6, "u": -6, "m": -3, "R": 0,
+	"k": 3, "K": 3, "M": 6, "G": 9, "T": 12, "P": 15, "E": 18, "Z": 21, "Y": 24,
+}
+
+const siPrefix = `(y|z|a|f|p|n|µ|μ|u|m|R|k|K|M|G|T|P|E|Z|Y)`
+
+var (
+	// a number, optionally followed by a prefix and a unit: "4.7 kΩ"
+	siPattern = regexp.MustCompile(`^([+-]?(?:\d+\.?\d*|\.\d+)(?:[eE][+-]?\d+)?) ?` + siPrefix + `?(\D*)$`)
+
+	// an RKM code, where the prefix is the decimal point: "4k7"
+	rkmPattern = regexp.MustCompile(`^([+-]?\d*)` + siPrefix + `(\d+)(\D*)$`)
+)
+
+// parseSIText parses a quantity written with an SI prefix or as an RKM code.
+// If unit is not empty the text must end with it. The unit that was found,
+// if any, is returned.
+func parseSIText(s string, unit string) (*big.Rat, string, bool) {
+	s = strings.TrimSpace(s)
+	if unit != "" {
+		if !strings.HasSuffix(s, unit) {
+			return nil, "", false
+		}
+		s = strings.TrimSpace(strings.TrimSuffix(s, unit))
+	}
+
+	var mant, prefix, rest string
+	if m := rkmPattern.FindStringSubmatch(s); m != nil && m[1] != "+" && m[1] != "-" {
+		mant, prefix, rest = m[1]+"."+m[3], m[2], m[4]
+	} else if m := siPattern.FindStringSubmatch(s); m != nil {
+		mant, prefix, rest = m[1], m[2], m[3]
+	} else {
+		return nil, "", false
+	}
+
+	if unit != "" && rest != "" {
+		return nil, "", false
+	}
+
+	r, ok := new(big.Rat).SetString(mant)
+	if !ok {
+		return nil, "", false
+	}
+
+	if power := siPowers[prefix]; power > 0 {
+		r.Mul(r, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(power)), nil)))
+	} else if power < 0 {
+		r.Quo(r, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(-power)), nil)))
+	}
+
+	if unit == "" {
+		unit = rest
+	}
+	return r, unit, true
+}
+
+// siDecimal converts an exact value parsed by parseSIText to a Decimal
+func siDecimal(r *big.Rat) Decimal {
+	prec, _ := r.FloatPrec()
+	return Decimal{r, prec}
+}
+
+// parseSI parses a quantity such as "4.7k", "100nF", "2M2" or "4R7" to an
+// exact Decimal. When called as `parseSI unit text` the text must end with
+// the unit, which is needed for units that start with a prefix, like "Pa".
+func (f *funcs) parseSI(args ...interface{}) (Decimal, error) {
+	if len(args) == 0 || len(args) > 2 {
+		return Decimal{}, argError("parseSI", -1, args, newNumError(ErrDomain, "expected [unit] text"))
+	}
+
+	unit := ""
+	if len(args) == 2 {
+		u, ok := args[0].(string)
+		if !ok {
+			return Decimal{}, argError("parseSI", 0, args[0], newNumError(ErrDomain, "unit must be a string"))
+		}
+		unit = u
+	}
+
+	v := args[len(args)-1]
+	s, ok := v.(string)
+	if !ok {
+		r, err := f.toRat(v)
+		if err != nil {
+			return Decimal{}, argError("parseSI", len(args)-1, v, err)
+		}
+		return siDecimal(r), nil
+	}
+
+	r, _, ok := parseSIText(s, unit)
+	if !ok {
+		// a plain number, which may be written in Options.Locale
+		if r, err := f.toRat(s); err == nil && unit == "" {
+			return siDecimal(r), nil
+		}
+		return Decimal{}, argError("parseSI", len(args)-1, v, newNumError(ErrNotNumber, "cannot parse %q as a quantity", s))
+	}
+	return siDecimal(r), nil
+}
@@ -0,0 +1,77 @@
+package sprigmath
+
+import (
+	"testing"
+)
+
+func TestParseSIText(t *testing.T) {
+	for _, tc := range []struct {
+		s, unit  string
+		expected string
+		found    string
+	}{
+		{"4.7k", "", "4700", ""},
+		{"100n", "", "1/10000000", ""},
+		{"2M2", "", "2200000", ""},
+		{"4k7", "", "4700", ""},
+		{"4R7", "", "47/10", ""},
+		{"0R22", "", "11/50", ""},
+		{"220nF", "", "11/50000000", "F"},
+		{"4.7 kΩ", "", "4700", "Ω"},
+		{"1.5MHz", "", "1500000", "Hz"},
+		{"10µA", "", "1/100000", "A"},
+		{"10uA", "", "1/100000", "A"},
+		{"-3m", "", "-3/1000", ""},
+		{"1e3k", "", "1000000", ""},
+		{"5F", "", "5", "F"},
+		{"101.3kPa", "Pa", "101300", "Pa"},
+		{"12", "", "12", ""},
+	} {
+		r, unit, ok := parseSIText(tc.s, tc.unit)
+		if !ok || r.RatString() != tc.expected || unit != tc.found {
+			t.Errorf("parseSIText(%q, %q): expected %s %q, got %v %q %v", tc.s, tc.unit, tc.expected, tc.found, r, unit, ok)
+		}
+	}
+
+	for _, s := range []string{"", "k", "4.7.1k", "abc", "1 2k"} {
+		if r, _, ok := parseSIText(s, ""); ok {
+			t.Errorf("parseSIText(%q): expected an error, got %v", s, r)
+		}
+	}
+	if r, _, ok := parseSIText("5kW", "Hz"); ok {
+		t.Errorf("Expected an error for the wrong unit, got %v", r)
+	}
+}
+
+func TestParseSI(t *testing.T) {
+	for tpl, expected := range map[string]string{
+		`{{ parseSI "4.7k" }}`:          "4700",
+		`{{ parseSI "220n" }}`:          "0.00000022",
+		`{{ parseSI "Pa" "101.3kPa" }}`: "101300",
+		`{{ "4k7" | parseSI | mul 2 }}`: "9400",
+		`{{ parseSI "100nF" | eng 2 }}`: "100 n",
+		`{{ parseSI 12 }}`:              "12",
+	} {
+		if out, err := runRaw(tpl, nil); err != nil || out != expected {
+			t.Errorf("%s: expected %q, got %q %v", tpl, expected, out, err)
+		}
+	}
+
+	if err := runerr(`{{ parseSI "x" }}`, `parseSI[arg0]: cannot parse "x" as a quantity`); err != nil {
+		t.Error(err)
+	}
+
+	opts := Options{SIPrefixes: true}
+	if out, err := runOpts(opts, `{{ add "4k7" "300" }} {{ number "2.2M" }} {{ mul "1m" 1000 }}`, nil); err != nil || out != "5000 2.2e+06 1" {
+		t.Errorf("Expected '5000 2.2e+06 1', got %q %v", out, err)
+	}
+	if out, err := runOpts(opts, `{{ roundCurrency "USD" "4k7" }} {{ toWords "en" "2k" }} {{ percent "1.5k" }}`, nil); err != nil || out != "4700.00 two thousand 15" {
+		t.Errorf("Expected '4700.00 two thousand 15', got %q %v", out, err)
+	}
+	if _, err := runOpts(opts, `{{ add "5kW" 1 }}`, nil); err == nil {
+		t.Error("Expected quantities with units to be rejected")
+	}
+	if err := runerr(`{{ add "4k7" 1 }}`, "add[arg0]: 4k7 is not a float64 or int64"); err != nil {
+		t.Error(err)
+	}
+}
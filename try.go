@@ -0,0 +1,100 @@
+package sprigmath
+
+import (
+	"reflect"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// call calls a function from the FuncMap with arguments from a template. An
+// error is returned for arguments that the function cannot accept, and when
+// the function returns an error or panics.
+func call(name string, fn interface{}, args []interface{}) (result interface{}, err error) {
+	fv := reflect.ValueOf(fn)
+	ft := fv.Type()
+
+	n := ft.NumIn()
+	if ft.IsVariadic() {
+		n--
+		if len(args) < n {
+			return nil, argError("try", -1, args, newNumError(ErrDomain, "%s takes at least %d arguments, got %d", name, n, len(args)))
+		}
+	} else if len(args) != n {
+		return nil, argError("try", -1, args, newNumError(ErrDomain, "%s takes %d arguments, got %d", name, n, len(args)))
+	}
+
+	in := make([]reflect.Value, len(args))
+	for i, a := range args {
+		var t reflect.Type
+		if i < n {
+			t = ft.In(i)
+		} else {
+			t = ft.In(n).Elem()
+		}
+
+		switch v := reflect.ValueOf(a); {
+		case !v.IsValid():
+			switch t.Kind() {
+			case reflect.Interface, reflect.Ptr, reflect.Map, reflect.Slice:
+				in[i] = reflect.Zero(t)
+			default:
+				return nil, argError(name, i, a, newNumError(ErrNotNumber, "missing value"))
+			}
+		case v.Type().AssignableTo(t):
+			in[i] = v
+		default:
+			return nil, argError(name, i, a, newNumError(ErrNotNumber, "cannot use %v as %s", a, t))
+		}
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			result, err = nil, argError(name, -1, args, newNumError(ErrDomain, "%v", r))
+		}
+	}()
+
+	out := fv.Call(in)
+	if len(out) == 2 && ft.Out(1) == errorType && !out[1].IsNil() {
+		return nil, out[1].Interface().(error)
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	return out[0].Interface(), nil
+}
+
+// try calls the function called name with the given arguments, and returns
+// the last argument instead if it fails, as in `try "sqrt" .x 0`. A missing
+// argument or a Null result is a failure whatever Options.Missing says, so
+// the fallback is used rather than 0 or nothing. Unknown functions and the
+// wrong number of arguments are still an error, as they are mistakes in the
+// template rather than in the data.
+func (f *funcs) try(name string, args ...interface{}) (interface{}, error) {
+	if len(args) == 0 {
+		return nil, argError("try", -1, args, newNumError(ErrDomain, "expected arguments and a fallback"))
+	}
+	fallback := args[len(args)-1]
+	args = args[:len(args)-1]
+
+	fn, ok := f.funcMap[name]
+	if !ok {
+		return nil, argError("try", 0, name, newNumError(ErrDomain, "unknown function %q", name))
+	}
+
+	r, err := call(name, fn, args)
+	if err != nil {
+		if e, ok := err.(*ArgError); ok && e.Func == "try" {
+			return nil, err
+		}
+		return fallback, nil
+	}
+	for _, a := range args {
+		if isMissing(a) {
+			return fallback, nil
+		}
+	}
+	if _, ok := r.(Null); ok {
+		return fallback, nil
+	}
+	return r, nil
+}
@@ -0,0 +1,58 @@
+package sprigmath
+
+import (
+	"testing"
+)
+
+func TestTry(t *testing.T) {
+	vars := map[string]interface{}{
+		"x":    16,
+		"neg":  -4,
+		"text": "abc",
+		"nil":  nil,
+	}
+
+	for tpl, expected := range map[string]string{
+		`{{ try "sqrt" .x 0 }}`:                   "4",
+		`{{ try "sqrt" .neg 0 }}`:                 "0",
+		`{{ try "sqrt" .text 0 }}`:                "0",
+		`{{ try "sqrt" .nil 0 }}`:                 "0",
+		`{{ try "sqrt" .missing 0 }}`:             "0",
+		`{{ try "div" 1 0 "n/a" }}`:               "n/a",
+		`{{ try "div" 3 2 "n/a" }}`:               "1.5",
+		`{{ try "add" 1 2 3 0 }}`:                 "6",
+		`{{ try "add" 1 "x" 3 0 }}`:               "0",
+		`{{ try "toWords" "en" .x "" }}`:          "sixteen",
+		`{{ try "toWords" "xx" .x "?" }}`:         "?",
+		`{{ try "toWords" 1 .x "?" }}`:            "?",
+		`{{ try "toRoman" 4000 "-" }}`:            "-",
+		`{{ try "assertPositive" "neg" .neg 1 }}`: "1",
+		`{{ try "pi" 0 | printf "%.2f" }}`:        "3.14",
+		`{{ try "try" "sqrt" .neg 1 2 }}`:         "1",
+	} {
+		if out, err := runOpts(Options{Strict: true}, tpl, vars); err != nil || out != expected {
+			t.Errorf("%s: expected %q, got %q %v", tpl, expected, out, err)
+		}
+	}
+
+	for tpl, errstr := range map[string]string{
+		`{{ try "sqrtt" 1 0 }}`:     `try[arg0]: unknown function "sqrtt"`,
+		`{{ try "sqrt" 0 }}`:        "try: sqrt takes 1 arguments, got 0",
+		`{{ try "atan2" 1 2 3 0 }}`: "try: atan2 takes 2 arguments, got 3",
+		`{{ try "add" 0 }}`:         "try: add takes at least 1 arguments, got 0",
+		`{{ try "sqrt" }}`:          "try: expected arguments and a fallback",
+	} {
+		if err := runerr(tpl, errstr); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestTryPanic(t *testing.T) {
+	f := &funcs{funcMap: map[string]interface{}{
+		"boom": func(v interface{}) int { panic("boom") },
+	}}
+	if r, err := f.try("boom", 1, 7); err != nil || r != 7 {
+		t.Errorf("expected the fallback for a panic, got %v %v", r, err)
+	}
+}
@@ -0,0 +1,575 @@
+package sprigmath
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// dimension holds the exponents of length, mass, time, electric current,
+// temperature, amount of substance, luminous intensity and information
+type dimension [8]int8
+
+func (d dimension) add(o dimension, sign int8) dimension {
+	for i := range d {
+		d[i] += o[i] * sign
+	}
+	return d
+}
+
+var (
+	dimLength  = dimension{1, 0, 0, 0, 0, 0, 0, 0}
+	dimMass    = dimension{0, 1, 0, 0, 0, 0, 0, 0}
+	dimTime    = dimension{0, 0, 1, 0, 0, 0, 0, 0}
+	dimCurrent = dimension{0, 0, 0, 1, 0, 0, 0, 0}
+	dimTemp    = dimension{0, 0, 0, 0, 1, 0, 0, 0}
+	dimAmount  = dimension{0, 0, 0, 0, 0, 1, 0, 0}
+	dimLight   = dimension{0, 0, 0, 0, 0, 0, 1, 0}
+	dimInfo    = dimension{0, 0, 0, 0, 0, 0, 0, 1}
+
+	dimArea      = dimension{2, 0, 0, 0, 0, 0, 0, 0}
+	dimVolume    = dimension{3, 0, 0, 0, 0, 0, 0, 0}
+	dimSpeed     = dimension{1, 0, -1, 0, 0, 0, 0, 0}
+	dimFrequency = dimension{0, 0, -1, 0, 0, 0, 0, 0}
+	dimForce     = dimension{1, 1, -2, 0, 0, 0, 0, 0}
+	dimPressure  = dimension{-1, 1, -2, 0, 0, 0, 0, 0}
+	dimEnergy    = dimension{2, 1, -2, 0, 0, 0, 0, 0}
+	dimPower     = dimension{2, 1, -3, 0, 0, 0, 0, 0}
+	dimVoltage   = dimension{2, 1, -3, -1, 0, 0, 0, 0}
+	dimDataRate  = dimension{0, 0, -1, 0, 0, 0, 0, 1}
+)
+
+// unitDef converts a unit to SI base units: si = value*factor + offset. The
+// factor and offset are exact, so that exact values convert exactly, and
+// offset is nil for units without one.
+type unitDef struct {
+	factor *big.Rat
+	offset *big.Rat
+	dim    dimension
+
+	// prefixable units may be written with an SI prefix, such as "km"
+	prefixable bool
+}
+
+// units is the table of units known to convert and quantity
+var units = map[string]unitDef{
+	// length
+	"m":   {rat("1"), nil, dimLength, true},
+	"in":  {rat("0.0254"), nil, dimLength, false},
+	"ft":  {rat("0.3048"), nil, dimLength, false},
+	"yd":  {rat("0.9144"), nil, dimLength, false},
+	"mi":  {rat("1609.344"), nil, dimLength, false},
+	"nmi": {rat("1852"), nil, dimLength, false},
+
+	// mass
+	"g":  {rat("0.001"), nil, dimMass, true},
+	"t":  {rat("1000"), nil, dimMass, false},
+	"lb": {rat("0.45359237"), nil, dimMass, false},
+	"oz": {rat("0.028349523125"), nil, dimMass, false},
+	"st": {rat("6.35029318"), nil, dimMass, false},
+
+	// time
+	"s":    {rat("1"), nil, dimTime, true},
+	"min":  {rat("60"), nil, dimTime, false},
+	"h":    {rat("3600"), nil, dimTime, false},
+	"day":  {rat("86400"), nil, dimTime, false},
+	"week": {rat("604800"), nil, dimTime, false},
+
+	// temperature
+	"K":    {rat("1"), nil, dimTemp, false},
+	"degC": {rat("1"), rat("273.15"), dimTemp, false},
+	"°C":   {rat("1"), rat("273.15"), dimTemp, false},
+	"degF": {rat("5/9"), rat("45967/180"), dimTemp, false}, // 273.15 - 32*5/9
+	"°F":   {rat("5/9"), rat("45967/180"), dimTemp, false},
+
+	// other SI base units
+	"A":   {rat("1"), nil, dimCurrent, true},
+	"mol": {rat("1"), nil, dimAmount, true},
+	"cd":  {rat("1"), nil, dimLight, false},
+
+	// area and volume
+	"ha":   {rat("10000"), nil, dimArea, false},
+	"acre": {rat("4046.8564224"), nil, dimArea, false},
+	"L":    {rat("0.001"), nil, dimVolume, true},
+	"l":    {rat("0.001"), nil, dimVolume, true},
+	"gal":  {rat("0.003785411784"), nil, dimVolume, false},
+	"qt":   {rat("0.000946352946"), nil, dimVolume, false},
+	"pt":   {rat("0.000473176473"), nil, dimVolume, false},
+	"floz": {rat("0.0000295735295625"), nil, dimVolume, false},
+
+	// speed
+	"mph": {rat("0.44704"), nil, dimSpeed, false},
+	"kn":  {rat("463/900"), nil, dimSpeed, false},
+
+	// derived units
+	"Hz":  {rat("1"), nil, dimFrequency, true},
+	"N":   {rat("1"), nil, dimForce, true},
+	"Pa":  {rat("1"), nil, dimPressure, true},
+	"bar": {rat("100000"), nil, dimPressure, false},
+	"atm": {rat("101325"), nil, dimPressure, false},
+	"psi": {rat("44482216152605/6451600000"), nil, dimPressure, false}, // lbf/in^2
+	"J":   {rat("1"), nil, dimEnergy, true},
+	"cal": {rat("4.184"), nil, dimEnergy, true},
+	"Wh":  {rat("3600"), nil, dimEnergy, true},
+	"W":   {rat("1"), nil, dimPower, true},
+	"hp":  {rat("745.69987158227022"), nil, dimPower, false}, // 550 ft*lbf/s
+	"V":   {rat("1"), nil, dimVoltage, true},
+
+	// information, with decimal SI prefixes and binary ones
+	"bit": {rat("1"), nil, dimInfo, true},
+	"b":   {rat("1"), nil, dimInfo, true},
+	"B":   {rat("8"), nil, dimInfo, true},
+	"KiB": {rat("8192"), nil, dimInfo, false},
+	"MiB": {rat("8388608"), nil, dimInfo, false},
+	"GiB": {rat("8589934592"), nil, dimInfo, false},
+	"TiB": {rat("8796093022208"), nil, dimInfo, false},
+	"bps": {rat("1"), nil, dimDataRate, true},
+}
+
+// unitPrefix is an SI prefix that can be used with prefixable units
+type unitPrefix struct {
+	prefix string
+	scale  *big.Rat
+}
+
+// unitPrefixes are tried in order, longest first, so that the unit found for
+// a name never depends on map iteration order
+var unitPrefixes = []unitPrefix{
+	{"µ", rat("1e-6")}, {"μ", rat("1e-6")},
+	{"n", rat("1e-9")}, {"u", rat("1e-6")}, {"m", rat("1e-3")}, {"c", rat("1e-2")}, {"d", rat("1e-1")},
+	{"k", rat("1e3")}, {"M", rat("1e6")}, {"G", rat("1e9")}, {"T", rat("1e12")}, {"P", rat("1e15")},
+}
+
+// lookupUnit finds a single unit such as "km" or "degF"
+func lookupUnit(name string) (unitDef, bool) {
+	if u, ok := units[name]; ok {
+		return u, true
+	}
+	for _, p := range unitPrefixes {
+		if !strings.HasPrefix(name, p.prefix) {
+			continue
+		}
+		if u, ok := units[name[len(p.prefix):]]; ok && u.prefixable {
+			u.factor = new(big.Rat).Mul(u.factor, p.scale)
+			return u, true
+		}
+	}
+	return unitDef{}, false
+}
+
+// rat parses an exact constant for the unit tables
+func rat(s string) *big.Rat {
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		panic("sprigmath: bad constant " + s)
+	}
+	return r
+}
+
+// toSI converts v in the unit to SI base units, exactly
+func (u unitDef) toSI(v *big.Rat) *big.Rat {
+	si := new(big.Rat).Mul(v, u.factor)
+	if u.offset != nil {
+		si.Add(si, u.offset)
+	}
+	return si
+}
+
+// fromSI converts si in SI base units to the unit, exactly
+func (u unitDef) fromSI(si *big.Rat) *big.Rat {
+	v := new(big.Rat).Set(si)
+	if u.offset != nil {
+		v.Sub(v, u.offset)
+	}
+	return v.Quo(v, u.factor)
+}
+
+// exactValue is v as an exact decimal, as toRat reads a float64, so that
+// 212 degF is exactly 100 degC. NaN and the infinities have none.
+func exactValue(v float64) (*big.Rat, bool) {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return nil, false
+	}
+	r, err := toRat(v)
+	return r, err == nil
+}
+
+// parseUnit parses a unit expression such as "km/h", "m/s^2" or "kg*m/s^2".
+// Units with an offset, like degC, can only be used on their own.
+func parseUnit(expr string) (unitDef, error) {
+	s := strings.Replace(strings.TrimSpace(expr), "·", "*", -1)
+	if s == "" {
+		return unitDef{}, newNumError(ErrDomain, "missing unit")
+	}
+
+	result := unitDef{factor: big.NewRat(1, 1)}
+	sign := int8(1)
+	terms := 0
+	for s != "" {
+		i := strings.IndexAny(s, "*/")
+		term := s
+		if i != -1 {
+			term = s[:i]
+		}
+
+		exp := 1
+		if j := strings.IndexByte(term, '^'); j != -1 {
+			e, err := strconv.Atoi(term[j+1:])
+			if err != nil {
+				return unitDef{}, newNumError(ErrDomain, "unknown unit %q", expr)
+			}
+			term, exp = term[:j], e
+		}
+
+		term = strings.TrimSpace(term)
+		if term != "1" {
+			u, ok := lookupUnit(term)
+			if !ok {
+				return unitDef{}, newNumError(ErrDomain, "unknown unit %q", term)
+			}
+			if u.offset != nil {
+				if i != -1 || terms != 0 || exp != 1 {
+					return unitDef{}, newNumError(ErrDomain, "%s cannot be combined with other units", term)
+				}
+				return u, nil
+			}
+			for k := 0; k < exp; k++ {
+				if sign > 0 {
+					result.factor = new(big.Rat).Mul(result.factor, u.factor)
+				} else {
+					result.factor = new(big.Rat).Quo(result.factor, u.factor)
+				}
+				result.dim = result.dim.add(u.dim, sign)
+			}
+		}
+		terms++
+
+		if i == -1 {
+			break
+		}
+		// everything after a / is in the denominator: "J/kg*K" is J/(kg*K)
+		if s[i] == '/' {
+			sign = -1
+		}
+		s = s[i+1:]
+	}
+	return result, nil
+}
+
+// Quantity is a value with a unit, such as 3 km/h. add, sub, mod, max, min
+// and the comparisons require quantities with the same dimension, mul and div
+// combine their units, and other functions use the value in its own unit.
+type Quantity struct {
+	value float64
+	unit  string
+	def   unitDef
+}
+
+// NewQuantity returns value in the given unit, such as "km/h" or "degC"
+func NewQuantity(value float64, unit string) (Quantity, error) {
+	def, err := parseUnit(unit)
+	if err != nil {
+		return Quantity{}, err
+	}
+	return Quantity{value, strings.TrimSpace(unit), def}, nil
+}
+
+// Value returns the value of q in its own unit
+func (q Quantity) Value() float64 {
+	return q.value
+}
+
+// Unit returns the unit of q
+func (q Quantity) Unit() string {
+	return q.unit
+}
+
+// Float64 implements Float64er
+func (q Quantity) Float64() (float64, error) {
+	return q.value, nil
+}
+
+func (q Quantity) String() string {
+	return strconv.FormatFloat(q.value, 'g', -1, 64) + " " + q.unit
+}
+
+// si returns the value of q in SI base units
+func (q Quantity) si() float64 {
+	if r, ok := exactValue(q.value); ok {
+		si, _ := q.def.toSI(r).Float64()
+		return si
+	}
+	factor, _ := q.def.factor.Float64()
+	return q.value * factor
+}
+
+// In converts q to another unit with the same dimension
+func (q Quantity) In(unit string) (Quantity, error) {
+	def, err := parseUnit(unit)
+	if err != nil {
+		return Quantity{}, err
+	}
+	if def.dim != q.def.dim {
+		return Quantity{}, newNumError(ErrDomain, "cannot convert %s to %s", q.unit, unit)
+	}
+	// NaN and the infinities are the same in every unit
+	value := q.value
+	if r, ok := exactValue(q.value); ok {
+		value, _ = def.fromSI(q.def.toSI(r)).Float64()
+	}
+	return Quantity{value, strings.TrimSpace(unit), def}, nil
+}
+
+func hasQuantity(args []interface{}) bool {
+	for _, arg := range args {
+		if _, ok := arg.(Quantity); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// asQuantity treats plain numbers as dimensionless quantities
+func (f *funcs) asQuantity(v interface{}) (Quantity, error) {
+	if q, ok := v.(Quantity); ok {
+		return q, nil
+	}
+	fv, err := f.toFloat64(v)
+	if err != nil {
+		return Quantity{}, err
+	}
+	return Quantity{fv, "", unitDef{factor: big.NewRat(1, 1)}}, nil
+}
+
+// quantitySum adds or subtracts quantities, which must have the same
+// dimension. The others are converted to the unit of the first quantity,
+// which is the unit of the result. They are converted as differences, so
+// adding 9 degF to 10 degC adds 5 degrees rather than -12.78.
+func (f *funcs) quantitySum(name string, args []interface{}, sign float64) (interface{}, error) {
+	acc, err := f.asQuantity(args[0])
+	if err != nil {
+		return nil, argError(name, 0, args[0], err)
+	}
+
+	for i, arg := range args[1:] {
+		q, err := f.asQuantity(arg)
+		if err != nil {
+			return nil, argError(name, i+1, arg, err)
+		}
+		if q.def.dim != acc.def.dim {
+			return nil, argError(name, i+1, arg, newNumError(ErrDomain, "cannot %s %s and %s", name, unitName(acc), unitName(q)))
+		}
+		ratio := new(big.Rat).Quo(q.def.factor, acc.def.factor)
+		a, aok := exactValue(acc.value)
+		b, bok := exactValue(q.value)
+		if aok && bok {
+			b.Mul(b, ratio)
+			if sign < 0 {
+				b.Neg(b)
+			}
+			acc.value, _ = a.Add(a, b).Float64()
+		} else {
+			rf, _ := ratio.Float64()
+			acc.value += sign * q.value * rf
+		}
+	}
+	return acc, nil
+}
+
+// quantityCompare compares two quantities with the same dimension in SI
+// units, so that 1 km is more than 900 m. ok is false if either is NaN.
+func (f *funcs) quantityCompare(name string, i int, a, b interface{}) (c int, ok bool, err error) {
+	qa, err := f.asQuantity(a)
+	if err != nil {
+		return 0, false, argError(name, i, a, err)
+	}
+	qb, err := f.asQuantity(b)
+	if err != nil {
+		return 0, false, argError(name, i+1, b, err)
+	}
+	if qa.def.dim != qb.def.dim {
+		return 0, false, argError(name, i+1, b, newNumError(ErrDomain, "cannot compare %s and %s", unitName(qa), unitName(qb)))
+	}
+
+	as, bs := qa.si(), qb.si()
+	switch {
+	case math.IsNaN(as) || math.IsNaN(bs):
+		return 0, false, nil
+	case as < bs:
+		return -1, true, nil
+	case as > bs:
+		return 1, true, nil
+	}
+	return 0, true, nil
+}
+
+// quantityExtreme returns the largest of args for max, with sign 1, or the
+// smallest for min, with sign -1. The result keeps its own unit.
+func (f *funcs) quantityExtreme(name string, args []interface{}, sign int) (interface{}, error) {
+	best := args[0]
+	for i, arg := range args[1:] {
+		c, ok, err := f.quantityCompare(name, i, best, arg)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return math.NaN(), nil
+		}
+		if c*sign < 0 {
+			best = arg
+		}
+	}
+	return best, nil
+}
+
+// quantityMod is the remainder of a divided by b, in the unit of a
+func (f *funcs) quantityMod(a, b interface{}) (interface{}, error) {
+	qa, err := f.asQuantity(a)
+	if err != nil {
+		return nil, argError("mod", 0, a, err)
+	}
+	qb, err := f.asQuantity(b)
+	if err != nil {
+		return nil, argError("mod", 1, b, err)
+	}
+	if qa.def.dim != qb.def.dim || qa.def.offset != nil || qb.def.offset != nil {
+		return nil, argError("mod", 1, b, newNumError(ErrDomain, "cannot mod %s and %s", unitName(qa), unitName(qb)))
+	}
+	if qb.value == 0 {
+		return nil, argError("mod", 1, b, ErrDivideByZero)
+	}
+	factor, _ := qa.def.factor.Float64()
+	qa.value = math.Mod(qa.si(), qb.si()) / factor
+	return qa, nil
+}
+
+func unitName(q Quantity) string {
+	if q.unit == "" {
+		return "a number"
+	}
+	return q.unit
+}
+
+// quantityProduct multiplies or divides quantities, combining their units.
+// A dimensionless result is returned as a plain float64.
+func (f *funcs) quantityProduct(name string, args []interface{}, divide bool) (interface{}, error) {
+	acc, err := f.asQuantity(args[0])
+	if err != nil {
+		return nil, argError(name, 0, args[0], err)
+	}
+
+	for i, arg := range args[1:] {
+		q, err := f.asQuantity(arg)
+		if err != nil {
+			return nil, argError(name, i+1, arg, err)
+		}
+		if (acc.def.offset != nil && q.unit != "") || q.def.offset != nil {
+			return nil, argError(name, i+1, arg, newNumError(ErrDomain, "cannot %s %s and %s", name, unitName(acc), unitName(q)))
+		}
+
+		if divide {
+			acc.value /= q.value
+			acc.def.factor = new(big.Rat).Quo(acc.def.factor, q.def.factor)
+			acc.def.dim = acc.def.dim.add(q.def.dim, -1)
+			acc.unit = joinUnits(acc.unit, "/", q.unit)
+		} else {
+			acc.value *= q.value
+			acc.def.factor = new(big.Rat).Mul(acc.def.factor, q.def.factor)
+			acc.def.dim = acc.def.dim.add(q.def.dim, 1)
+			acc.unit = joinUnits(acc.unit, "*", q.unit)
+		}
+	}
+
+	if acc.def.dim == (dimension{}) {
+		if r, ok := exactValue(acc.value); ok {
+			v, _ := r.Mul(r, acc.def.factor).Float64()
+			return v, nil
+		}
+		factor, _ := acc.def.factor.Float64()
+		return acc.value * factor, nil
+	}
+	return acc, nil
+}
+
+func joinUnits(a, op, b string) string {
+	an, ad := splitUnit(a)
+	bn, bd := splitUnit(b)
+	if op == "/" {
+		bn, bd = bd, bn
+	}
+
+	num := strings.Join(append(an, bn...), "*")
+	den := strings.Join(append(ad, bd...), "*")
+	switch {
+	case den == "":
+		return num
+	case num == "":
+		return "1/" + den
+	}
+	return num + "/" + den
+}
+
+// splitUnit splits a unit expression into the factors above and below the
+// line, as parseUnit reads it
+func splitUnit(expr string) (num, den []string) {
+	if expr == "" {
+		return nil, nil
+	}
+	top, bottom := expr, ""
+	if i := strings.IndexByte(expr, '/'); i != -1 {
+		top, bottom = expr[:i], strings.Replace(expr[i+1:], "/", "*", -1)
+	}
+	if top != "1" {
+		num = strings.Split(top, "*")
+	}
+	if bottom != "" {
+		den = strings.Split(bottom, "*")
+	}
+	return num, den
+}
+
+// quantity makes a Quantity from a value and a unit, such as
+// `quantity 3 "km/h"`
+func (f *funcs) quantity(v interface{}, unit string) (Quantity, error) {
+	fv, err := f.toFloat64(v)
+	if err != nil {
+		return Quantity{}, argError("quantity", 0, v, err)
+	}
+	q, err := NewQuantity(fv, unit)
+	if err != nil {
+		return Quantity{}, argError("quantity", 1, unit, err)
+	}
+	return q, nil
+}
+
+// toUnit converts a quantity to another unit with the same dimension
+func (f *funcs) toUnit(unit string, v interface{}) (Quantity, error) {
+	q, ok := v.(Quantity)
+	if !ok {
+		return Quantity{}, argError("toUnit", 1, v, newNumError(ErrNotNumber, "%v is not a quantity", v))
+	}
+	r, err := q.In(unit)
+	if err != nil {
+		return Quantity{}, argError("toUnit", 0, unit, err)
+	}
+	return r, nil
+}
+
+// convert converts a value from one unit to another, such as
+// `convert 212 "degF" "degC"`
+func (f *funcs) convert(v interface{}, from string, to string) (float64, error) {
+	fv, err := f.toFloat64(v)
+	if err != nil {
+		return 0, argError("convert", 0, v, err)
+	}
+	q, err := NewQuantity(fv, from)
+	if err != nil {
+		return 0, argError("convert", 1, from, err)
+	}
+	r, err := q.In(to)
+	if err != nil {
+		return 0, argError("convert", 2, to, err)
+	}
+	return r.value, nil
+}
@@ -0,0 +1,138 @@
+package sprigmath
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestParseUnit(t *testing.T) {
+	for expr, expected := range map[string]unitDef{
+		"km":       {rat("1000"), nil, dimLength, true},
+		"km/h":     {rat("1000/3600"), nil, dimSpeed, false},
+		"m/s^2":    {rat("1"), nil, dimension{1, 0, -2, 0, 0, 0, 0, 0}, false},
+		"kg*m/s^2": {rat("1"), nil, dimForce, false},
+		"J/kg*K":   {rat("1"), nil, dimension{2, 0, -2, 0, -1, 0, 0, 0}, false},
+		"1/s":      {rat("1"), nil, dimFrequency, false},
+		"Mbit/s":   {rat("1e6"), nil, dimDataRate, false},
+		"degF":     units["degF"],
+	} {
+		u, err := parseUnit(expr)
+		if err != nil || u.factor.Cmp(expected.factor) != 0 || u.offset != expected.offset || u.dim != expected.dim {
+			t.Errorf("parseUnit(%q): expected %v %v %v, got %v %v %v %v", expr,
+				expected.factor, expected.offset, expected.dim, u.factor, u.offset, u.dim, err)
+		}
+	}
+
+	for _, expr := range []string{"", "furlong", "degC/s", "m^x", "kmph"} {
+		if u, err := parseUnit(expr); err == nil {
+			t.Errorf("parseUnit(%q): expected an error, got %v", expr, u)
+		}
+	}
+}
+
+func TestUnitPrefixOrder(t *testing.T) {
+	for i := 1; i < len(unitPrefixes); i++ {
+		if len(unitPrefixes[i].prefix) > len(unitPrefixes[i-1].prefix) {
+			t.Errorf("prefix %q is longer than %q before it", unitPrefixes[i].prefix, unitPrefixes[i-1].prefix)
+		}
+	}
+	for name, factor := range map[string]*big.Rat{"µs": rat("1e-6"), "mm": rat("1e-3"), "mmol": rat("1e-3"), "kB": rat("8e3")} {
+		if u, ok := lookupUnit(name); !ok || u.factor.Cmp(factor) != 0 {
+			t.Errorf("%s: expected factor %v, got %v %v", name, factor, u.factor, ok)
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+	for _, tc := range []struct {
+		v        float64
+		from, to string
+		expected float64
+	}{
+		{212, "degF", "degC", 100},
+		{-40, "degC", "degF", -40},
+		{0, "degC", "K", 273.15},
+		{100, "km/h", "mph", 62.13711922373339},
+		{1, "mi", "km", 1.609344},
+		{1, "GiB", "MB", 1073.741824},
+		{100, "Mbit/s", "MB/s", 12.5},
+		{1, "kWh", "J", 3.6e6},
+		{1, "atm", "psi", 14.695948775513449},
+		{1, "gal", "L", 3.785411784},
+	} {
+		f := &funcs{}
+		if r, err := f.convert(tc.v, tc.from, tc.to); err != nil || !floatEquals(r, tc.expected) {
+			t.Errorf("convert %v %s %s: expected %v, got %v %v", tc.v, tc.from, tc.to, tc.expected, r, err)
+		}
+	}
+
+	// exact inputs convert exactly, without float rounding in the offsets
+	for tpl, expected := range map[string]string{
+		`{{ convert 212 "degF" "degC" }}`:                    "100",
+		`{{ convert 100 "degC" "degF" }}`:                    "212",
+		`{{ convert 98.6 "°F" "°C" }}`:                       "37",
+		`{{ convert 0.1 "m" "cm" }}`:                         "10",
+		`{{ quantity 212 "degF" | toUnit "degC" }}`:          "100 degC",
+		`{{ add (quantity 10 "degC") (quantity 9 "degF") }}`: "15 degC",
+	} {
+		if err := runt(tpl, expected); err != nil {
+			t.Errorf("%s: %v", tpl, err)
+		}
+	}
+
+	if err := runerr(`{{ convert 1 "m" "s" }}`, "convert[arg2]: cannot convert m to s"); err != nil {
+		t.Error(err)
+	}
+	if err := runerr(`{{ convert 1 "furlong" "m" }}`, `convert[arg1]: unknown unit "furlong"`); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestQuantity(t *testing.T) {
+	for tpl, expected := range map[string]string{
+		`{{ quantity 3 "km" }}`:                                              "3 km",
+		`{{ add (quantity 1 "km") (quantity 500 "m") }}`:                     "1.5 km",
+		`{{ sub (quantity 20 "degC") (quantity 5 "degC") }}`:                 "15 degC",
+		`{{ add (quantity 10 "degC") (quantity 9 "degF") }}`:                 "15 degC",
+		`{{ sub (quantity 300 "K") (quantity 10 "degC") }}`:                  "290 K",
+		`{{ add (quantity 1 "ft") (quantity 6 "in") }}`:                      "1.5 ft",
+		`{{ div (quantity 100 "km") (quantity 2 "h") }}`:                     "50 km/h",
+		`{{ div (quantity 100 "km") (quantity 2 "h") | toUnit "m/s" }}`:      "13.88888888888889 m/s",
+		`{{ mul (quantity 2 "m") (quantity 3 "m") }}`:                        "6 m*m",
+		`{{ mul (quantity 2 "m") 3 }}`:                                       "6 m",
+		`{{ div (quantity 1 "km") (quantity 1 "m") }}`:                       "1000",
+		`{{ div 1 (quantity 4 "s") | toUnit "Hz" }}`:                         "0.25 Hz",
+		`{{ div (quantity 1 "m") (quantity 2 "s") | div (quantity 4 "m") }}`: "8 m*s/m",
+		`{{ quantity 9 "m" | sqrt }}`:                                        "3",
+		`{{ max (quantity 1 "km") (quantity 900 "m") }}`:                     "1 km",
+		`{{ min (quantity 1 "km") (quantity 900 "m") (quantity 1 "mi") }}`:   "900 m",
+		`{{ numEq (quantity 1 "km") (quantity 1000 "m") }}`:                  "true",
+		`{{ numLt (quantity 1 "mi") (quantity 1 "km") }}`:                    "false",
+		`{{ numGt (quantity 50 "degF") (quantity 5 "degC") }}`:               "true",
+		`{{ between (quantity 1 "m") (quantity 1 "km") (quantity 4 "ft") }}`: "true",
+		`{{ mod (quantity 1 "km") (quantity 300 "m") }}`:                     "0.1 km",
+	} {
+		if out, err := runRaw(tpl, nil); err != nil || out != expected {
+			t.Errorf("%s: expected %q, got %q %v", tpl, expected, out, err)
+		}
+	}
+
+	for tpl, errstr := range map[string]string{
+		`{{ add (quantity 1 "m") (quantity 1 "s") }}`:    "add[arg1]: cannot add m and s",
+		`{{ add (quantity 1 "m") 1 }}`:                   "add[arg1]: cannot add m and a number",
+		`{{ mul (quantity 1 "degC") (quantity 1 "m") }}`: "mul[arg1]: cannot mul degC and m",
+		`{{ toUnit "s" (quantity 1 "m") }}`:              "toUnit[arg0]: cannot convert m to s",
+		`{{ max (quantity 1 "m") (quantity 1 "s") }}`:    "max[arg1]: cannot compare m and s",
+		`{{ numEq (quantity 1 "m") 1 }}`:                 "numEq[arg1]: cannot compare m and a number",
+		`{{ mod (quantity 1 "degC") (quantity 1 "K") }}`: "mod[arg1]: cannot mod degC and K",
+	} {
+		_, err := runRaw(tpl, nil)
+		if !errors.Is(err, ErrDomain) {
+			t.Errorf("%s: expected ErrDomain, got %v", tpl, err)
+		} else if err = testError(errstr, err); err != nil {
+			t.Errorf("%s: %v", tpl, err)
+		}
+	}
+}
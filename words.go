@@ -0,0 +1,234 @@
+package sprigmath
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Language spells numbers as words for toWords and ordinal. Register more
+// languages with RegisterLanguage.
+type Language interface {
+	// Words spells n, such as "one hundred twenty-three"
+	Words(n int64) string
+
+	// Ordinal returns n followed by its ordinal suffix, such as "21st"
+	Ordinal(n int64) string
+}
+
+var (
+	languagesMu sync.RWMutex
+	languages   = map[string]Language{
+		"en": english{},
+	}
+)
+
+// languageKey normalises a language tag, so that "en_US" and "EN-us" match
+func languageKey(tag string) string {
+	return strings.ToLower(strings.Replace(strings.TrimSpace(tag), "_", "-", -1))
+}
+
+// RegisterLanguage makes a Language available to toWords and ordinal under a
+// BCP 47 language tag such as "fr". It is safe to call at any time.
+func RegisterLanguage(tag string, lang Language) {
+	languagesMu.Lock()
+	defer languagesMu.Unlock()
+	languages[languageKey(tag)] = lang
+}
+
+func lookupLanguage(tag string) (Language, error) {
+	languagesMu.RLock()
+	defer languagesMu.RUnlock()
+
+	key := languageKey(tag)
+	if lang, ok := languages[key]; ok {
+		return lang, nil
+	}
+	if i := strings.IndexByte(key, '-'); i != -1 {
+		if lang, ok := languages[key[:i]]; ok {
+			return lang, nil
+		}
+	}
+	return nil, newNumError(ErrDomain, "unknown language %q", tag)
+}
+
+// english spells numbers in English, using the short scale
+type english struct{}
+
+var (
+	englishOnes = []string{
+		"zero", "one", "two", "three", "four", "five", "six", "seven", "eight", "nine",
+		"ten", "eleven", "twelve", "thirteen", "fourteen", "fifteen", "sixteen",
+		"seventeen", "eighteen", "nineteen",
+	}
+	englishTens = []string{
+		"", "", "twenty", "thirty", "forty", "fifty", "sixty", "seventy", "eighty", "ninety",
+	}
+	englishScales = []string{
+		"", "thousand", "million", "billion", "trillion", "quadrillion", "quintillion",
+	}
+)
+
+// below1000 spells 0 < n < 1000
+func (english) below1000(n uint64) string {
+	var words []string
+	if n >= 100 {
+		words = append(words, englishOnes[n/100], "hundred")
+		n %= 100
+	}
+	if n >= 20 {
+		if n%10 != 0 {
+			words = append(words, englishTens[n/10]+"-"+englishOnes[n%10])
+		} else {
+			words = append(words, englishTens[n/10])
+		}
+	} else if n > 0 {
+		words = append(words, englishOnes[n])
+	}
+	return strings.Join(words, " ")
+}
+
+func (e english) Words(n int64) string {
+	if n == 0 {
+		return englishOnes[0]
+	}
+
+	// negate as unsigned so that math.MinInt64 works
+	u := uint64(n)
+	prefix := ""
+	if n < 0 {
+		u = -u
+		prefix = "minus "
+	}
+
+	var parts []string
+	for scale := 0; u > 0; scale++ {
+		if chunk := u % 1000; chunk != 0 {
+			part := e.below1000(chunk)
+			if englishScales[scale] != "" {
+				part += " " + englishScales[scale]
+			}
+			parts = append([]string{part}, parts...)
+		}
+		u /= 1000
+	}
+	return prefix + strings.Join(parts, " ")
+}
+
+func (english) Ordinal(n int64) string {
+	abs := n % 100
+	if abs < 0 {
+		abs = -abs
+	}
+
+	suffix := "th"
+	if abs < 11 || abs > 13 {
+		switch abs % 10 {
+		case 1:
+			suffix = "st"
+		case 2:
+			suffix = "nd"
+		case 3:
+			suffix = "rd"
+		}
+	}
+	return strconv.FormatInt(n, 10) + suffix
+}
+
+var romanNumerals = []struct {
+	value  int64
+	symbol string
+}{
+	{1000, "M"}, {900, "CM"}, {500, "D"}, {400, "CD"},
+	{100, "C"}, {90, "XC"}, {50, "L"}, {40, "XL"},
+	{10, "X"}, {9, "IX"}, {5, "V"}, {4, "IV"}, {1, "I"},
+}
+
+// toWords spells a number in a language: `toWords "en" 123` is
+// "one hundred twenty-three"
+func (f *funcs) toWords(lang string, v interface{}) (string, error) {
+	l, err := lookupLanguage(lang)
+	if err != nil {
+		return "", argError("toWords", 0, lang, err)
+	}
+
+	whole, err := f.toIntRange(v, "int64", math.MinInt64, math.MaxInt64)
+	if err != nil {
+		return "", argError("toWords", 1, v, err)
+	}
+	n := whole.Int64()
+	return l.Words(n), nil
+}
+
+// ordinal returns a number with its English ordinal suffix, or with the
+// suffix of a language when called as `ordinal lang n`
+func (f *funcs) ordinal(args ...interface{}) (string, error) {
+	var l Language = english{}
+	if len(args) == 2 {
+		lang, ok := args[0].(string)
+		if !ok {
+			return "", argError("ordinal", 0, args[0], newNumError(ErrDomain, "language must be a string"))
+		}
+		var err error
+		if l, err = lookupLanguage(lang); err != nil {
+			return "", argError("ordinal", 0, lang, err)
+		}
+	} else if len(args) != 1 {
+		return "", argError("ordinal", -1, args, newNumError(ErrDomain, "expected [language] number"))
+	}
+
+	v := args[len(args)-1]
+	whole, err := f.toIntRange(v, "int64", math.MinInt64, math.MaxInt64)
+	if err != nil {
+		return "", argError("ordinal", len(args)-1, v, err)
+	}
+	n := whole.Int64()
+	return l.Ordinal(n), nil
+}
+
+// toRoman writes 1 through 3999 as a Roman numeral
+func (f *funcs) toRoman(v interface{}) (string, error) {
+	whole, err := f.toIntRange(v, "int64", math.MinInt64, math.MaxInt64)
+	if err != nil {
+		return "", argError("toRoman", 0, v, err)
+	}
+	n := whole.Int64()
+	if n < 1 || n > 3999 {
+		return "", argError("toRoman", 0, v, newNumError(ErrDomain, "%v is not between 1 and 3999", n))
+	}
+
+	var b strings.Builder
+	for _, r := range romanNumerals {
+		for n >= r.value {
+			b.WriteString(r.symbol)
+			n -= r.value
+		}
+	}
+	return b.String(), nil
+}
+
+// fromRoman parses a Roman numeral. Only the canonical form is accepted, so
+// "IIII" and "IC" are errors.
+func (f *funcs) fromRoman(s string) (int64, error) {
+	text := strings.ToUpper(strings.TrimSpace(s))
+
+	var n int64
+	rest := text
+	for _, r := range romanNumerals {
+		for strings.HasPrefix(rest, r.symbol) {
+			n += r.value
+			rest = rest[len(r.symbol):]
+		}
+	}
+
+	if text == "" || rest != "" || n > 3999 {
+		return 0, argError("fromRoman", 0, s, newNumError(ErrNotNumber, "%q is not a Roman numeral", s))
+	}
+
+	// reject non-canonical numerals by writing n back out
+	if canonical, _ := f.toRoman(n); canonical != text {
+		return 0, argError("fromRoman", 0, s, newNumError(ErrNotNumber, "%q is not a Roman numeral", s))
+	}
+	return n, nil
+}
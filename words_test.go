@@ -0,0 +1,78 @@
+package sprigmath
+
+import (
+	"strconv"
+	"testing"
+)
+
+type shouting struct{ english }
+
+func (s shouting) Ordinal(n int64) string {
+	return strconv.FormatInt(n, 10) + "!"
+}
+
+func TestWords(t *testing.T) {
+	for n, expected := range map[int64]string{
+		0:                    "zero",
+		7:                    "seven",
+		21:                   "twenty-one",
+		100:                  "one hundred",
+		123:                  "one hundred twenty-three",
+		-40:                  "minus forty",
+		1000001:              "one million one",
+		2500000012:           "two billion five hundred million twelve",
+		-9223372036854775808: "minus nine quintillion two hundred twenty-three quadrillion three hundred seventy-two trillion thirty-six billion eight hundred fifty-four million seven hundred seventy-five thousand eight hundred eight",
+	} {
+		if s := (english{}).Words(n); s != expected {
+			t.Errorf("Words(%d): expected %q, got %q", n, expected, s)
+		}
+	}
+
+	for n, expected := range map[int64]string{
+		1: "1st", 2: "2nd", 3: "3rd", 4: "4th", 11: "11th", 12: "12th", 13: "13th",
+		21: "21st", 102: "102nd", 111: "111th", -1: "-1st", 0: "0th",
+	} {
+		if s := (english{}).Ordinal(n); s != expected {
+			t.Errorf("Ordinal(%d): expected %q, got %q", n, expected, s)
+		}
+	}
+
+	RegisterLanguage("X_Shout", shouting{})
+	t.Cleanup(func() {
+		languagesMu.Lock()
+		defer languagesMu.Unlock()
+		delete(languages, "x-shout")
+	})
+	for tpl, expected := range map[string]string{
+		`{{ toWords "en-US" "123" }}`:  "one hundred twenty-three",
+		`{{ 21 | ordinal }}`:           "21st",
+		`{{ ordinal "x-shout" 3 }}`:    "3!",
+		`{{ ordinal "x_SHOUT" 3 }}`:    "3!",
+		`{{ toRoman 2026 }}`:           "MMXXVI",
+		`{{ toRoman 3999 }}`:           "MMMCMXCIX",
+		`{{ fromRoman "mcmxciv" }}`:    "1994",
+		`{{ toRoman 14 | fromRoman }}`: "14",
+	} {
+		if out, err := runRaw(tpl, nil); err != nil || out != expected {
+			t.Errorf("%s: expected %q, got %q %v", tpl, expected, out, err)
+		}
+	}
+
+	for tpl, errstr := range map[string]string{
+		`{{ toRoman 4000 }}`:       "toRoman[arg0]: 4000 is not between 1 and 3999",
+		`{{ toRoman 0 }}`:          "toRoman[arg0]: 0 is not between 1 and 3999",
+		`{{ fromRoman "IIII" }}`:   `fromRoman[arg0]: "IIII" is not a Roman numeral`,
+		`{{ fromRoman "IC" }}`:     `fromRoman[arg0]: "IC" is not a Roman numeral`,
+		`{{ fromRoman "" }}`:       `fromRoman[arg0]: "" is not a Roman numeral`,
+		`{{ toWords "xx" 1 }}`:     `toWords[arg0]: unknown language "xx"`,
+		`{{ toWords "en" "bob" }}`: "toWords[arg1]: bob is not a float64 or int64",
+		`{{ toWords "en" 1e20 }}`:  "toWords[arg1]: 1e+20 overflows int64",
+		`{{ toWords "en" 3.9 }}`:   "toWords[arg1]: 3.9 is not a whole number",
+		`{{ ordinal 2.5 }}`:        "ordinal[arg0]: 2.5 is not a whole number",
+		`{{ toRoman 4.5 }}`:        "toRoman[arg0]: 4.5 is not a whole number",
+	} {
+		if err := runerr(tpl, errstr); err != nil {
+			t.Errorf("%s: %v", tpl, err)
+		}
+	}
+}